@@ -0,0 +1,381 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Loader fetches the raw bytes of a schema document identified by uri. The default loader
+// understands the file:// and http(s):// schemes, matching what FromURI supported before the
+// Compiler existed; implementations can override it to serve schemas from memory, embed.FS, an
+// authenticated endpoint, or anywhere else.
+type Loader interface {
+	Load(uri string) ([]byte, error)
+}
+
+// LoaderFunc adapts a function to a Loader.
+type LoaderFunc func(uri string) ([]byte, error)
+
+func (f LoaderFunc) Load(uri string) ([]byte, error) {
+	return f(uri)
+}
+
+// defaultLoader is the Loader used by FromURI and by Compilers that have not been given one
+// of their own via SetLoader.
+var defaultLoader Loader = LoaderFunc(func(uri string) ([]byte, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "file", "":
+		return ioutil.ReadFile(u.Path)
+	case "http", "https":
+		resp, err := http.Get(u.String())
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != 200 {
+			return nil, fmt.Errorf("HTTP(S) URI returned a non-200 response: %d", resp.StatusCode)
+		}
+		return ioutil.ReadAll(resp.Body)
+	default:
+		return nil, fmt.Errorf("unsupported URI scheme: %q", u.Scheme)
+	}
+})
+
+// node is an entry in a Compiler's resolution index: a schema reachable at an absolute URI
+// (whose fragment, if any, is the JSON pointer or $anchor it was found at), together with the
+// base URI in effect for it.
+type node struct {
+	schema  *Schema
+	baseURI string
+}
+
+// Compiler resolves $id/$anchor/$ref/$recursiveRef/$recursiveAnchor across one or more schema
+// documents into a single in-memory graph. Documents can be supplied directly via AddResource
+// or fetched on demand (and cached) through the Loader.
+type Compiler struct {
+	loader    Loader
+	resources map[string][]byte // raw documents added via AddResource, keyed by URI
+	index     map[string]*node  // absolute URI (with #pointer or #anchor) -> resolved node
+	compiling map[string]bool   // absolute document URIs currently being compiled, for cycle detection
+}
+
+// NewCompiler returns a Compiler that fetches un-added resources using the default
+// file://-and-http(s):// loader.
+func NewCompiler() *Compiler {
+	return &Compiler{
+		loader:    defaultLoader,
+		resources: map[string][]byte{},
+		index:     map[string]*node{},
+		compiling: map[string]bool{},
+	}
+}
+
+// SetLoader overrides the Loader used to fetch resources that were not supplied via
+// AddResource.
+func (c *Compiler) SetLoader(l Loader) {
+	c.loader = l
+}
+
+// AddResource registers the contents of r as the schema document identified by uri, so
+// Compile can resolve $ref/$recursiveRef against it without fetching it over the network or
+// filesystem.
+func (c *Compiler) AddResource(uri string, r io.Reader) error {
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	c.resources[uri] = bs
+	return nil
+}
+
+// CompiledSchema is the result of resolving a schema document's $id/$anchor/$ref graph.
+type CompiledSchema struct {
+	// Root is the schema at the URI passed to Compile.
+	Root *Schema
+	// URI is the absolute URI Root was compiled from.
+	URI string
+
+	compiler *Compiler
+}
+
+// Resolve looks up a previously indexed $id, $anchor, or absolute URI plus JSON pointer
+// fragment, returning the Schema it points to.
+func (cs *CompiledSchema) Resolve(absoluteRef string) (*Schema, error) {
+	n, ok := cs.compiler.index[absoluteRef]
+	if !ok {
+		return nil, fmt.Errorf("jsonschema: unresolved reference %q", absoluteRef)
+	}
+	return n.schema, nil
+}
+
+// Validate validates instance against cs.Root like Schema.Validate, except $ref/$recursiveRef
+// are resolved against the Compiler's full $id/$anchor/$ref index, so references that cross
+// into other documents (or target an $anchor under a different $id) work too, not just
+// same-document "#/..." pointers.
+func (cs *CompiledSchema) Validate(instance interface{}) (*Result, error) {
+	res := &Result{}
+	ctx := &validationCtx{result: res, resolver: &compiledResolver{cs: cs}, dynamicScope: []*Schema{cs.Root}}
+	ctx.validate(cs.Root, instance, "", "")
+	return res, nil
+}
+
+// ValidateBytes unmarshals instance as JSON and validates it against cs, like Validate.
+func (cs *CompiledSchema) ValidateBytes(instance []byte) (*Result, error) {
+	var v interface{}
+	if err := json.Unmarshal(instance, &v); err != nil {
+		return nil, err
+	}
+	return cs.Validate(v)
+}
+
+// compiledResolver resolves $ref/$recursiveRef by looking them up in a CompiledSchema's
+// Compiler index, which Compile has already populated for every $id/$anchor/$ref reachable
+// from the root document (and any documents fetched while resolving those).
+type compiledResolver struct {
+	cs *CompiledSchema
+}
+
+func (r *compiledResolver) Resolve(ref string) (*Schema, error) {
+	if ref == "#" {
+		return r.cs.Root, nil
+	}
+	abs, err := resolveURI(r.cs.URI, ref)
+	if err != nil {
+		return nil, err
+	}
+	return r.cs.Resolve(abs)
+}
+
+// Compile fetches (or reuses an AddResource'd copy of) the document at uri, then indexes every
+// $id, $anchor, and $ref reachable from it - following $ref across document boundaries and
+// fetching whatever it points to - detecting reference cycles along the way. The returned
+// CompiledSchema's Root is ready to pass to Validate; any $ref nodes within it have already been
+// indexed and can be followed via Resolve. $recursiveRef/$recursiveAnchor are resolved
+// dynamically at validation time, not here; see CompiledSchema.Validate.
+func (c *Compiler) Compile(uri string) (*CompiledSchema, error) {
+	root, baseURI, err := c.fetch(uri)
+	if err != nil {
+		return nil, err
+	}
+	var pending []pendingRef
+	if err := c.walk(root, baseURI, "", baseURI, &pending); err != nil {
+		return nil, err
+	}
+	// $ref values are resolved only after the whole document (and everything it
+	// reaches via $ref) has been indexed, so a $ref to a $defs entry later in the same
+	// document - by far the most common shape - finds its target. Resolving pending is a
+	// queue, not a fixed-length loop, because resolving a cross-document $ref walks (and so
+	// indexes) a new document that may itself contain further $refs.
+	for i := 0; i < len(pending); i++ {
+		p := pending[i]
+		if _, err := c.resolveRef(p.baseURI, p.ref, &pending); err != nil {
+			return nil, err
+		}
+	}
+	return &CompiledSchema{Root: root, URI: baseURI, compiler: c}, nil
+}
+
+// pendingRef is a $ref encountered while walking a document, queued for resolution once the
+// whole document has been indexed.
+type pendingRef struct {
+	baseURI string
+	ref     string
+}
+
+func (c *Compiler) fetch(uri string) (*Schema, string, error) {
+	abs := uri
+	if bs, ok := c.resources[uri]; ok {
+		s := &Schema{}
+		if err := json.Unmarshal(bs, s); err != nil {
+			return nil, "", err
+		}
+		return s, abs, nil
+	}
+	bs, err := c.loader.Load(uri)
+	if err != nil {
+		return nil, "", err
+	}
+	s := &Schema{}
+	if err := json.Unmarshal(bs, s); err != nil {
+		return nil, "", err
+	}
+	return s, abs, nil
+}
+
+// indexKey returns the index key for the schema at pointer (relative to baseURI's document).
+// pointer == "" is the document/resource root, keyed as the bare baseURI - the same form
+// resolveURI produces for a $ref with no fragment - not "baseURI#", which no $ref ever resolves
+// to.
+func indexKey(baseURI, pointer string) string {
+	if pointer == "" {
+		return baseURI
+	}
+	return baseURI + "#" + pointer
+}
+
+// walk registers s and everything reachable from it (via $id, $anchor, and in-place
+// applicators) into the index, queueing any $ref it finds onto pending for resolution once the
+// whole document has been indexed; a $recursiveRef other than "#" is rejected. baseURI is the absolute URI of the
+// document s was parsed from. $recursiveRef's dynamic-scope resolution happens at validation
+// time, in validate.go, not here - by the time a schema is being validated, the compiler can no
+// longer tell which schema resources the dynamic scope actually passed through.
+func (c *Compiler) walk(s *Schema, baseURI, pointer string, docURI string, pending *[]pendingRef) error {
+	if s == nil {
+		return nil
+	}
+	if _, ok := s.AsBool(); ok {
+		return nil
+	}
+	sc := s.schema
+	if sc == nil {
+		return nil
+	}
+
+	if sc.ID != nil {
+		resolved, err := resolveURI(baseURI, *sc.ID)
+		if err != nil {
+			return err
+		}
+		baseURI = resolved
+	}
+	c.index[indexKey(baseURI, pointer)] = &node{schema: s, baseURI: baseURI}
+	if sc.Anchor != nil {
+		c.index[baseURI+"#"+*sc.Anchor] = &node{schema: s, baseURI: baseURI}
+	}
+
+	children := []*Schema{sc.Not, sc.If, sc.Then, sc.Else, sc.Contains, sc.AdditionalItems,
+		sc.UnevaluatedItems, sc.PropertyNames, sc.AdditionalProperties, sc.UnevaluatedProperties}
+	childNames := []string{"not", "if", "then", "else", "contains", "additionalItems",
+		"unevaluatedItems", "propertyNames", "additionalProperties", "unevaluatedProperties"}
+	for i, child := range children {
+		if err := c.walk(child, baseURI, pointer+"/"+childNames[i], docURI, pending); err != nil {
+			return err
+		}
+	}
+	for name, group := range map[string][]*Schema{"allOf": sc.AllOf, "anyOf": sc.AnyOf, "oneOf": sc.OneOf} {
+		for i, child := range group {
+			if err := c.walk(child, baseURI, fmt.Sprintf("%s/%s/%d", pointer, name, i), docURI, pending); err != nil {
+				return err
+			}
+		}
+	}
+	for name, child := range sc.Properties {
+		if err := c.walk(child, baseURI, pointer+"/properties/"+jsonPointerEscape(name), docURI, pending); err != nil {
+			return err
+		}
+	}
+	for name, child := range sc.PatternProperties {
+		if err := c.walk(child, baseURI, pointer+"/patternProperties/"+jsonPointerEscape(name), docURI, pending); err != nil {
+			return err
+		}
+	}
+	for name, child := range sc.DependentSchemas {
+		if err := c.walk(child, baseURI, pointer+"/dependentSchemas/"+jsonPointerEscape(name), docURI, pending); err != nil {
+			return err
+		}
+	}
+	for name, child := range sc.Defs {
+		if err := c.walk(child, baseURI, pointer+"/$defs/"+jsonPointerEscape(name), docURI, pending); err != nil {
+			return err
+		}
+	}
+	for name, child := range sc.Definitions {
+		if err := c.walk(child, baseURI, pointer+"/definitions/"+jsonPointerEscape(name), docURI, pending); err != nil {
+			return err
+		}
+	}
+	if tuple, ok := sc.Items.([]interface{}); ok {
+		for i, raw := range tuple {
+			if err := c.walk(schemaFromRaw(raw), baseURI, fmt.Sprintf("%s/items/%d", pointer, i), docURI, pending); err != nil {
+				return err
+			}
+		}
+	} else if sc.Items != nil {
+		if err := c.walk(schemaFromRaw(sc.Items), baseURI, pointer+"/items", docURI, pending); err != nil {
+			return err
+		}
+	}
+
+	if sc.Ref != nil {
+		// Queued rather than resolved here: the rest of this document (e.g. a $defs entry
+		// later in the same object) may not be indexed yet. See Compile.
+		*pending = append(*pending, pendingRef{baseURI: baseURI, ref: *sc.Ref})
+	}
+	if sc.RecursiveRef != nil && *sc.RecursiveRef != "#" {
+		return fmt.Errorf("jsonschema: unsupported $recursiveRef %q, only \"#\" is supported", *sc.RecursiveRef)
+	}
+
+	return nil
+}
+
+// resolveRef resolves ref against baseURI, fetching and indexing (via AddResource or the
+// Loader) the document it targets if that document has not been seen yet. Any further $ref
+// found while indexing that document is appended to pending, same as during the initial walk.
+func (c *Compiler) resolveRef(baseURI, ref string, pending *[]pendingRef) (*node, error) {
+	abs, err := resolveURI(baseURI, ref)
+	if err != nil {
+		return nil, err
+	}
+	if n, ok := c.index[abs]; ok {
+		return n, nil
+	}
+	docURI := abs
+	if i := strings.IndexByte(docURI, '#'); i >= 0 {
+		docURI = docURI[:i]
+	}
+	if c.compiling[docURI] {
+		return nil, nil // cycle: the referenced document is still being walked
+	}
+	c.compiling[docURI] = true
+	defer delete(c.compiling, docURI)
+
+	doc, docBaseURI, err := c.fetch(docURI)
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema: resolving %q: %w", ref, err)
+	}
+	if err := c.walk(doc, docBaseURI, "", docBaseURI, pending); err != nil {
+		return nil, err
+	}
+	if n, ok := c.index[abs]; ok {
+		return n, nil
+	}
+	return nil, fmt.Errorf("jsonschema: unresolved reference %q", abs)
+}
+
+// resolveURI resolves ref against the base URI, the way $id/$ref resolution is defined in
+// terms of RFC 3986 URI reference resolution.
+func resolveURI(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return b.ResolveReference(r).String(), nil
+}
+
+// FromURI loads a single schema document from uri using the default file://-and-http(s)://
+// loader. It does not resolve $ref; use NewCompiler().Compile for schemas that reference
+// other documents or fragments.
+func FromURI(uri string) (*Schema, error) {
+	bs, err := defaultLoader.Load(uri)
+	if err != nil {
+		return nil, err
+	}
+	s := &Schema{}
+	if err := json.Unmarshal(bs, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,164 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompilerResolvesForwardDefsRef(t *testing.T) {
+	c := NewCompiler()
+	if err := c.AddResource("https://example.com/schema", strings.NewReader(`{
+		"$id": "https://example.com/schema",
+		"properties": {"foo": {"$ref": "#/$defs/bar"}},
+		"$defs": {"bar": {"type": "string"}}
+	}`)); err != nil {
+		t.Fatalf("AddResource: %v", err)
+	}
+
+	cs, err := c.Compile("https://example.com/schema")
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	res, err := cs.Validate(mustUnmarshalInstance(t, `{"foo": 5}`))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if res.Valid() {
+		t.Fatalf("expected {\"foo\": 5} to fail $ref'd type:string, got valid")
+	}
+
+	res, err = cs.Validate(mustUnmarshalInstance(t, `{"foo": "ok"}`))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if !res.Valid() {
+		t.Fatalf("expected {\"foo\": \"ok\"} to pass, got errors: %v", res.Errors)
+	}
+}
+
+func TestCompilerResolvesCrossDocumentRef(t *testing.T) {
+	c := NewCompiler()
+	if err := c.AddResource("https://example.com/a", strings.NewReader(`{
+		"$id": "https://example.com/a",
+		"properties": {"foo": {"$ref": "https://example.com/b#/$defs/bar"}}
+	}`)); err != nil {
+		t.Fatalf("AddResource a: %v", err)
+	}
+	if err := c.AddResource("https://example.com/b", strings.NewReader(`{
+		"$id": "https://example.com/b",
+		"$defs": {"bar": {"type": "string"}}
+	}`)); err != nil {
+		t.Fatalf("AddResource b: %v", err)
+	}
+
+	cs, err := c.Compile("https://example.com/a")
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	res, err := cs.Validate(mustUnmarshalInstance(t, `{"foo": 5}`))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if res.Valid() {
+		t.Fatalf("expected {\"foo\": 5} to fail the cross-document $ref'd type:string, got valid")
+	}
+
+	res, err = cs.Validate(mustUnmarshalInstance(t, `{"foo": "ok"}`))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if !res.Valid() {
+		t.Fatalf("expected {\"foo\": \"ok\"} to pass, got errors: %v", res.Errors)
+	}
+}
+
+func TestCompilerResolvesBareCrossDocumentRef(t *testing.T) {
+	c := NewCompiler()
+	if err := c.AddResource("https://example.com/root", strings.NewReader(`{
+		"$id": "https://example.com/root",
+		"properties": {"foo": {"$ref": "https://example.com/target"}}
+	}`)); err != nil {
+		t.Fatalf("AddResource root: %v", err)
+	}
+	if err := c.AddResource("https://example.com/target", strings.NewReader(`{"type": "integer"}`)); err != nil {
+		t.Fatalf("AddResource target: %v", err)
+	}
+
+	cs, err := c.Compile("https://example.com/root")
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	res, err := cs.Validate(mustUnmarshalInstance(t, `{"foo": "not an int"}`))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if res.Valid() {
+		t.Fatalf("expected a $ref with no fragment to the root of another document to be resolved")
+	}
+}
+
+func TestCompiledSchemaRecursiveRefUsesOutermostAnchor(t *testing.T) {
+	c := NewCompiler()
+	if err := c.AddResource("https://example.com/node", strings.NewReader(`{
+		"$id": "https://example.com/node",
+		"$recursiveAnchor": true,
+		"type": "object",
+		"properties": {
+			"children": {"type": "array", "items": {"$recursiveRef": "#"}}
+		}
+	}`)); err != nil {
+		t.Fatalf("AddResource node: %v", err)
+	}
+	if err := c.AddResource("https://example.com/extended-node", strings.NewReader(`{
+		"$id": "https://example.com/extended-node",
+		"$recursiveAnchor": true,
+		"allOf": [{"$ref": "https://example.com/node"}],
+		"required": ["label"]
+	}`)); err != nil {
+		t.Fatalf("AddResource extended-node: %v", err)
+	}
+
+	cs, err := c.Compile("https://example.com/extended-node")
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	res, err := cs.Validate(mustUnmarshalInstance(t, `{"label": "root", "children": [{"label": "ok", "children": []}]}`))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if !res.Valid() {
+		t.Fatalf("expected nested children satisfying the extension's \"required\" to pass, got errors: %v", res.Errors)
+	}
+
+	res, err = cs.Validate(mustUnmarshalInstance(t, `{"label": "root", "children": [{"children": []}]}`))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if res.Valid() {
+		t.Fatalf("expected $recursiveRef to resolve to the extension schema (outermost $recursiveAnchor), so a nested child missing \"label\" should fail")
+	}
+}
+
+func TestCompiledSchemaValidateBytes(t *testing.T) {
+	c := NewCompiler()
+	if err := c.AddResource("https://example.com/schema", strings.NewReader(`{"type": "integer"}`)); err != nil {
+		t.Fatalf("AddResource: %v", err)
+	}
+
+	cs, err := c.Compile("https://example.com/schema")
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	res, err := cs.ValidateBytes([]byte(`"not an integer"`))
+	if err != nil {
+		t.Fatalf("ValidateBytes: %v", err)
+	}
+	if res.Valid() {
+		t.Fatalf("expected a string to fail type:integer")
+	}
+}
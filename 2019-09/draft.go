@@ -0,0 +1,404 @@
+package jsonschema
+
+import "encoding/json"
+
+// Draft identifies a JSON Schema specification version. Schemas from different drafts differ
+// in which keywords exist and what some of them mean (e.g. whether exclusiveMinimum is a
+// number or a boolean sibling of minimum), so UnmarshalJSON uses a Draft to normalize a
+// document into the single schema struct this package validates against.
+type Draft struct {
+	// Name is a short human-readable identifier, e.g. "2019-09".
+	Name string
+	// SchemaURI is the canonical "$schema" value for this draft.
+	SchemaURI string
+	// IDKeyword is "$id" for drafts >= 6, or "id" for draft-04.
+	IDKeyword string
+	// DefsKeyword is "$defs" for drafts >= 2019-09, or "definitions" for earlier drafts.
+	DefsKeyword string
+	// BooleanExclusiveBounds is true for draft-04, where exclusiveMinimum/exclusiveMaximum
+	// are booleans modifying the meaning of the sibling minimum/maximum, rather than
+	// standalone numeric keywords.
+	BooleanExclusiveBounds bool
+	// DependenciesKeyword is true for drafts < 2019-09, where "dependencies" maps a property
+	// name to either a schema (dependentSchemas) or an array of required properties
+	// (dependentRequired).
+	DependenciesKeyword bool
+	// Keywords is the set of validation/applicator keywords this draft assigns meaning to,
+	// keyed by the 2019-09-shaped name normalize rewrites older drafts to (e.g. "dependentSchemas",
+	// never "dependencies"). UnmarshalJSON decodes every keyword field regardless of draft, so
+	// Keywords lets validate.go ignore a keyword's accidental presence in a document whose draft
+	// never defined it, rather than treating it as a genuine assertion. See Recognizes.
+	Keywords map[string]bool
+}
+
+// Recognizes reports whether keyword is a validation/applicator keyword this draft defines.
+// Keywords with no draft-to-draft variance (e.g. "type", "properties") are never checked against
+// this table by validate.go; Recognizes only gates the keywords that were added, removed, or
+// reshaped across drafts.
+func (d Draft) Recognizes(keyword string) bool {
+	return d.Keywords[keyword]
+}
+
+func keywordSet(keys ...string) map[string]bool {
+	m := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		m[k] = true
+	}
+	return m
+}
+
+func withKeywords(base map[string]bool, add ...string) map[string]bool {
+	m := make(map[string]bool, len(base)+len(add))
+	for k := range base {
+		m[k] = true
+	}
+	for _, k := range add {
+		m[k] = true
+	}
+	return m
+}
+
+func withoutKeywords(base map[string]bool, remove ...string) map[string]bool {
+	m := make(map[string]bool, len(base))
+	for k := range base {
+		m[k] = true
+	}
+	for _, k := range remove {
+		delete(m, k)
+	}
+	return m
+}
+
+// draft4Keywords covers the keywords validate.go conditionally gates that draft-04 already
+// defines. Keywords with no draft-to-draft variance aren't listed here; see Recognizes.
+var draft4Keywords = keywordSet("dependentSchemas", "dependentRequired")
+
+var draft6Keywords = withKeywords(draft4Keywords, "const", "contains", "propertyNames")
+
+var draft7Keywords = withKeywords(draft6Keywords, "if", "then", "else")
+
+var draft201909Keywords = withKeywords(draft7Keywords,
+	"unevaluatedProperties", "unevaluatedItems", "maxContains", "minContains", "$recursiveRef")
+
+var draft202012Keywords = withKeywords(withoutKeywords(draft201909Keywords, "$recursiveRef"),
+	"prefixItems")
+
+var (
+	Draft4 = Draft{
+		Name:                   "draft-04",
+		SchemaURI:              "http://json-schema.org/draft-04/schema#",
+		IDKeyword:              "id",
+		DefsKeyword:            "definitions",
+		BooleanExclusiveBounds: true,
+		DependenciesKeyword:    true,
+		Keywords:               draft4Keywords,
+	}
+	Draft6 = Draft{
+		Name:                "draft-06",
+		SchemaURI:           "http://json-schema.org/draft-06/schema#",
+		IDKeyword:           "$id",
+		DefsKeyword:         "definitions",
+		DependenciesKeyword: true,
+		Keywords:            draft6Keywords,
+	}
+	Draft7 = Draft{
+		Name:                "draft-07",
+		SchemaURI:           "http://json-schema.org/draft-07/schema#",
+		IDKeyword:           "$id",
+		DefsKeyword:         "definitions",
+		DependenciesKeyword: true,
+		Keywords:            draft7Keywords,
+	}
+	Draft201909 = Draft{
+		Name:        "2019-09",
+		SchemaURI:   "https://json-schema.org/draft/2019-09/schema",
+		IDKeyword:   "$id",
+		DefsKeyword: "$defs",
+		Keywords:    draft201909Keywords,
+	}
+	Draft202012 = Draft{
+		Name:        "2020-12",
+		SchemaURI:   "https://json-schema.org/draft/2020-12/schema",
+		IDKeyword:   "$id",
+		DefsKeyword: "$defs",
+		Keywords:    draft202012Keywords,
+	}
+)
+
+// defaultDraft is used when a document has no "$schema" keyword, matching this package's
+// historical behavior of assuming 2019-09 semantics throughout.
+var defaultDraft = Draft201909
+
+var knownDrafts = []Draft{Draft4, Draft6, Draft7, Draft201909, Draft202012}
+
+// DetectDraft inspects the "$schema" keyword of a schema document and returns the matching
+// Draft, or defaultDraft if "$schema" is absent or unrecognized.
+func DetectDraft(schema []byte) Draft {
+	var peek struct {
+		Schema string `json:"$schema"`
+	}
+	if err := json.Unmarshal(schema, &peek); err != nil || peek.Schema == "" {
+		return defaultDraft
+	}
+	for _, d := range knownDrafts {
+		if stripFragment(d.SchemaURI) == stripFragment(peek.Schema) {
+			return d
+		}
+	}
+	return defaultDraft
+}
+
+// propagateDraft stamps s, the schema UnmarshalJSON just decoded, with d (the draft detected from
+// s's own "$schema", or defaultDraft), then propagates d into every schema nested under it.
+// Subschemas almost never repeat "$schema" themselves and would otherwise default to
+// defaultDraft regardless of their document's actual draft, so validate.go could not gate
+// draft-specific keywords correctly for them without this. Subschemas under "items" are kept
+// undecoded (schema.Items is an interface{}) and are not reached here: when validated they are
+// decoded on their own and get the draft of their own "$schema", or defaultDraft.
+func propagateDraft(s *Schema, d Draft) {
+	if s == nil || s.schema == nil {
+		return
+	}
+	sc := s.schema
+	sc.draft = d
+
+	for _, sub := range sc.Properties {
+		propagateInto(sub, d)
+	}
+	for _, sub := range sc.PatternProperties {
+		propagateInto(sub, d)
+	}
+	for _, sub := range sc.DependentSchemas {
+		propagateInto(sub, d)
+	}
+	for _, sub := range sc.Defs {
+		propagateInto(sub, d)
+	}
+	for _, sub := range sc.Definitions {
+		propagateInto(sub, d)
+	}
+	for _, sub := range sc.AllOf {
+		propagateInto(sub, d)
+	}
+	for _, sub := range sc.AnyOf {
+		propagateInto(sub, d)
+	}
+	for _, sub := range sc.OneOf {
+		propagateInto(sub, d)
+	}
+	for _, sub := range sc.PrefixItems {
+		propagateInto(sub, d)
+	}
+	propagateInto(sc.AdditionalProperties, d)
+	propagateInto(sc.UnevaluatedProperties, d)
+	propagateInto(sc.PropertyNames, d)
+	propagateInto(sc.AdditionalItems, d)
+	propagateInto(sc.UnevaluatedItems, d)
+	propagateInto(sc.Contains, d)
+	propagateInto(sc.Not, d)
+	propagateInto(sc.If, d)
+	propagateInto(sc.Then, d)
+	propagateInto(sc.Else, d)
+}
+
+// propagateInto propagates d into sub, unless sub carries its own "$schema" (UnmarshalJSON
+// already gave it the matching draft during decoding), in which case propagation continues below
+// sub using its own draft instead.
+func propagateInto(sub *Schema, d Draft) {
+	if sub == nil || sub.schema == nil {
+		return
+	}
+	if sub.schema.Schema != nil {
+		d = sub.schema.draft
+	}
+	propagateDraft(sub, d)
+}
+
+func stripFragment(uri string) string {
+	for i := 0; i < len(uri); i++ {
+		if uri[i] == '#' {
+			return uri[:i]
+		}
+	}
+	return uri
+}
+
+// normalize rewrites data, a draft-specific schema document, into the keyword shapes this
+// package's schema struct expects (2019-09), so that documents from older drafts can be
+// decoded by the same UnmarshalJSON. It is a best-effort syntactic translation of the
+// keywords whose name or shape changed between drafts; it does not change validation
+// semantics (e.g. it does not synthesize dependentRequired's stricter checks).
+func normalize(d Draft, data []byte) ([]byte, error) {
+	if d.IDKeyword == "$id" && !d.BooleanExclusiveBounds && !d.DependenciesKeyword {
+		return data, nil // already 2019-09-shaped
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		// Not a schema object (e.g. `true`/`false`, or malformed); let the normal decoder
+		// report the error.
+		return data, nil
+	}
+
+	if d.IDKeyword == "id" {
+		if v, ok := raw["id"]; ok {
+			raw["$id"] = v
+			delete(raw, "id")
+		}
+	}
+
+	if d.BooleanExclusiveBounds {
+		normalizeBooleanExclusiveBound(raw, "exclusiveMinimum", "minimum")
+		normalizeBooleanExclusiveBound(raw, "exclusiveMaximum", "maximum")
+	}
+
+	if d.DependenciesKeyword {
+		if v, ok := raw["dependencies"]; ok {
+			dependentSchemas, dependentRequired, err := splitDependencies(v)
+			if err != nil {
+				return nil, err
+			}
+			if len(dependentSchemas) > 0 {
+				bs, err := json.Marshal(dependentSchemas)
+				if err != nil {
+					return nil, err
+				}
+				raw["dependentSchemas"] = bs
+			}
+			if len(dependentRequired) > 0 {
+				bs, err := json.Marshal(dependentRequired)
+				if err != nil {
+					return nil, err
+				}
+				raw["dependentRequired"] = bs
+			}
+			delete(raw, "dependencies")
+		}
+	}
+
+	// Recurse into nested schema-bearing containers so $id/dependencies normalization applies
+	// throughout the document, not just at the root.
+	for _, key := range []string{"properties", "patternProperties", "definitions", "$defs", "dependentSchemas"} {
+		v, ok := raw[key]
+		if !ok {
+			continue
+		}
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(v, &m); err != nil {
+			continue
+		}
+		for name, sub := range m {
+			normalized, err := normalize(d, sub)
+			if err != nil {
+				return nil, err
+			}
+			m[name] = normalized
+		}
+		bs, err := json.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		raw[key] = bs
+	}
+	if v, ok := raw["items"]; ok {
+		// draft-04/06/07 also allow "items" to be the tuple form (an array of per-position
+		// schemas), not just a single schema applied to every item - normalize each element
+		// instead of assuming a single schema, or a tuple's boolean exclusiveMinimum/etc. (and
+		// any other per-draft rewrite) would silently never apply.
+		var tuple []json.RawMessage
+		if err := json.Unmarshal(v, &tuple); err == nil {
+			for i, sub := range tuple {
+				normalized, err := normalize(d, sub)
+				if err != nil {
+					return nil, err
+				}
+				tuple[i] = normalized
+			}
+			bs, err := json.Marshal(tuple)
+			if err != nil {
+				return nil, err
+			}
+			raw["items"] = bs
+		} else {
+			normalized, err := normalize(d, v)
+			if err != nil {
+				return nil, err
+			}
+			raw["items"] = normalized
+		}
+	}
+	for _, key := range []string{"additionalItems", "additionalProperties", "not", "if", "then", "else", "contains", "propertyNames"} {
+		v, ok := raw[key]
+		if !ok {
+			continue
+		}
+		normalized, err := normalize(d, v)
+		if err != nil {
+			return nil, err
+		}
+		raw[key] = normalized
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		v, ok := raw[key]
+		if !ok {
+			continue
+		}
+		var arr []json.RawMessage
+		if err := json.Unmarshal(v, &arr); err != nil {
+			continue
+		}
+		for i, sub := range arr {
+			normalized, err := normalize(d, sub)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = normalized
+		}
+		bs, err := json.Marshal(arr)
+		if err != nil {
+			return nil, err
+		}
+		raw[key] = bs
+	}
+
+	return json.Marshal(raw)
+}
+
+func normalizeBooleanExclusiveBound(raw map[string]json.RawMessage, exclusiveKey, boundKey string) {
+	v, ok := raw[exclusiveKey]
+	if !ok {
+		return
+	}
+	var isExclusive bool
+	if err := json.Unmarshal(v, &isExclusive); err != nil {
+		return // already numeric (e.g. a >=draft-06 document erroneously tagged draft-04)
+	}
+	if !isExclusive {
+		delete(raw, exclusiveKey)
+		return
+	}
+	bound, ok := raw[boundKey]
+	if !ok {
+		delete(raw, exclusiveKey)
+		return
+	}
+	raw[exclusiveKey] = bound
+	delete(raw, boundKey)
+}
+
+func splitDependencies(data json.RawMessage) (schemas map[string]json.RawMessage, required map[string][]string, err error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, err
+	}
+	schemas = map[string]json.RawMessage{}
+	required = map[string][]string{}
+	for prop, v := range raw {
+		var list []string
+		if err := json.Unmarshal(v, &list); err == nil {
+			required[prop] = list
+			continue
+		}
+		schemas[prop] = v
+	}
+	return schemas, required, nil
+}
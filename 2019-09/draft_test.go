@@ -0,0 +1,63 @@
+package jsonschema
+
+import "testing"
+
+func TestDetectDraft(t *testing.T) {
+	cases := []struct {
+		doc  string
+		want string
+	}{
+		{`{"$schema": "http://json-schema.org/draft-04/schema#"}`, Draft4.Name},
+		{`{"$schema": "https://json-schema.org/draft/2020-12/schema"}`, Draft202012.Name},
+		{`{"type": "string"}`, defaultDraft.Name},
+	}
+	for _, c := range cases {
+		if got := DetectDraft([]byte(c.doc)); got.Name != c.want {
+			t.Errorf("DetectDraft(%s) = %q, want %q", c.doc, got.Name, c.want)
+		}
+	}
+}
+
+func TestPrefixItemsGatedByDraft(t *testing.T) {
+	s := mustUnmarshalSchema(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"prefixItems": [{"type": "string"}, {"type": "integer"}],
+		"items": {"type": "boolean"}
+	}`)
+
+	res, _ := s.Validate(mustUnmarshalInstance(t, `["a", 1, true, false]`))
+	if !res.Valid() {
+		t.Errorf("expected prefixItems+items to validate, got errors: %v", res.Errors)
+	}
+
+	res, _ = s.Validate(mustUnmarshalInstance(t, `["a", 1, "oops"]`))
+	if res.Valid() {
+		t.Errorf("expected the trailing item to be checked against items:boolean")
+	}
+}
+
+func TestNormalizeBooleanExclusiveBoundInTupleItems(t *testing.T) {
+	s := mustUnmarshalSchema(t, `{
+		"$schema": "http://json-schema.org/draft-04/schema#",
+		"items": [{"minimum": 0, "exclusiveMinimum": true}]
+	}`)
+
+	res, _ := s.Validate(mustUnmarshalInstance(t, `[0]`))
+	if res.Valid() {
+		t.Errorf("expected draft-04's boolean exclusiveMinimum to be normalized inside tuple items, rejecting 0")
+	}
+
+	res, _ = s.Validate(mustUnmarshalInstance(t, `[1]`))
+	if !res.Valid() {
+		t.Errorf("expected 1 to satisfy exclusiveMinimum: 0, got errors: %v", res.Errors)
+	}
+}
+
+func TestConstIgnoredUnderDraft04(t *testing.T) {
+	s := mustUnmarshalSchema(t, `{"$schema": "http://json-schema.org/draft-04/schema#", "const": "x"}`)
+
+	res, _ := s.Validate("y")
+	if !res.Valid() {
+		t.Errorf("draft-04 has no const keyword, expected it to be ignored, got errors: %v", res.Errors)
+	}
+}
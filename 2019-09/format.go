@@ -0,0 +1,225 @@
+package jsonschema
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FormatChecker validates that input satisfies some named "format" assertion
+// (https://json-schema.org/draft/2019-09/json-schema-validation.html#rfc.section.7).
+//
+// IsFormat is called with the raw instance value. Per the draft's annotation-vs-assertion
+// semantics, a checker should return true for any input it does not apply to (e.g. a
+// string-only checker receiving a non-string instance) rather than treating it as a failure.
+type FormatChecker interface {
+	IsFormat(input interface{}) bool
+}
+
+// FormatCheckerFunc adapts a function to a FormatChecker.
+type FormatCheckerFunc func(input interface{}) bool
+
+func (f FormatCheckerFunc) IsFormat(input interface{}) bool {
+	return f(input)
+}
+
+var (
+	formatsMu sync.RWMutex
+	formats   = map[string]FormatChecker{}
+)
+
+func init() {
+	RegisterFormat("date-time", FormatCheckerFunc(isDateTime))
+	RegisterFormat("date", FormatCheckerFunc(isDate))
+	RegisterFormat("time", FormatCheckerFunc(isTime))
+	RegisterFormat("duration", FormatCheckerFunc(isDuration))
+	RegisterFormat("email", FormatCheckerFunc(isEmail))
+	RegisterFormat("hostname", FormatCheckerFunc(isHostname))
+	RegisterFormat("ipv4", FormatCheckerFunc(isIPv4))
+	RegisterFormat("ipv6", FormatCheckerFunc(isIPv6))
+	RegisterFormat("uri", FormatCheckerFunc(isURI))
+	RegisterFormat("uri-reference", FormatCheckerFunc(isURIReference))
+	RegisterFormat("uuid", FormatCheckerFunc(isUUID))
+	RegisterFormat("regex", FormatCheckerFunc(isRegex))
+	RegisterFormat("json-pointer", FormatCheckerFunc(isJSONPointer))
+	RegisterFormat("relative-json-pointer", FormatCheckerFunc(isRelativeJSONPointer))
+}
+
+// RegisterFormat registers (or replaces) the checker used for the "format" keyword value
+// name. It is safe to call concurrently with validation, including after schemas have
+// already been parsed.
+func RegisterFormat(name string, checker FormatChecker) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	formats[name] = checker
+}
+
+// Unregister removes the checker for name, if any. Schemas using "format": name will then
+// pass validation unconditionally, per the draft's behavior for unknown formats.
+func Unregister(name string) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	delete(formats, name)
+}
+
+// Formats returns the names of all currently registered format checkers.
+func Formats() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	return names
+}
+
+func lookupFormat(name string) (FormatChecker, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	c, ok := formats[name]
+	return c, ok
+}
+
+var (
+	dateRE                = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	timeRE                = regexp.MustCompile(`(?i)^\d{2}:\d{2}:\d{2}(\.\d+)?(z|[+-]\d{2}:\d{2})$`)
+	durationRE            = regexp.MustCompile(`^P(\d+[YMWD])+(T(\d+[HMS])+)?$|^PT(\d+[HMS])+$`)
+	hostnameRE            = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+	emailRE               = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	uuidRE                = regexp.MustCompile(`(?i)^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	jsonPointerRE         = regexp.MustCompile(`^(/([^/~]|~0|~1)*)*$`)
+	relativeJSONPointerRE = regexp.MustCompile(`^\d+(#|(/([^/~]|~0|~1)*)*)$`)
+)
+
+func asString(input interface{}) (string, bool) {
+	s, ok := input.(string)
+	return s, ok
+}
+
+func isDateTime(input interface{}) bool {
+	s, ok := asString(input)
+	if !ok {
+		return true
+	}
+	_, err := time.Parse(time.RFC3339Nano, s)
+	return err == nil
+}
+
+func isDate(input interface{}) bool {
+	s, ok := asString(input)
+	if !ok {
+		return true
+	}
+	if !dateRE.MatchString(s) {
+		return false
+	}
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
+
+func isTime(input interface{}) bool {
+	s, ok := asString(input)
+	if !ok {
+		return true
+	}
+	return timeRE.MatchString(s)
+}
+
+func isDuration(input interface{}) bool {
+	s, ok := asString(input)
+	if !ok {
+		return true
+	}
+	return durationRE.MatchString(s)
+}
+
+func isEmail(input interface{}) bool {
+	s, ok := asString(input)
+	if !ok {
+		return true
+	}
+	return emailRE.MatchString(s)
+}
+
+func isHostname(input interface{}) bool {
+	s, ok := asString(input)
+	if !ok {
+		return true
+	}
+	if len(s) > 253 {
+		return false
+	}
+	return hostnameRE.MatchString(s)
+}
+
+func isIPv4(input interface{}) bool {
+	s, ok := asString(input)
+	if !ok {
+		return true
+	}
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() != nil && strings.Count(s, ":") == 0
+}
+
+func isIPv6(input interface{}) bool {
+	s, ok := asString(input)
+	if !ok {
+		return true
+	}
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() == nil
+}
+
+func isURI(input interface{}) bool {
+	s, ok := asString(input)
+	if !ok {
+		return true
+	}
+	u, err := url.Parse(s)
+	return err == nil && u.IsAbs()
+}
+
+func isURIReference(input interface{}) bool {
+	s, ok := asString(input)
+	if !ok {
+		return true
+	}
+	_, err := url.Parse(s)
+	return err == nil
+}
+
+func isUUID(input interface{}) bool {
+	s, ok := asString(input)
+	if !ok {
+		return true
+	}
+	return uuidRE.MatchString(s)
+}
+
+func isRegex(input interface{}) bool {
+	s, ok := asString(input)
+	if !ok {
+		return true
+	}
+	_, err := regexp.Compile(s)
+	return err == nil
+}
+
+func isJSONPointer(input interface{}) bool {
+	s, ok := asString(input)
+	if !ok {
+		return true
+	}
+	return jsonPointerRE.MatchString(s)
+}
+
+func isRelativeJSONPointer(input interface{}) bool {
+	s, ok := asString(input)
+	if !ok {
+		return true
+	}
+	return relativeJSONPointerRE.MatchString(s)
+}
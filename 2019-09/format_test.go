@@ -0,0 +1,74 @@
+package jsonschema
+
+import "testing"
+
+func TestFormatCheckers(t *testing.T) {
+	cases := []struct {
+		format string
+		value  string
+		want   bool
+	}{
+		{"date-time", "2021-01-02T15:04:05Z", true},
+		{"date-time", "not-a-date-time", false},
+		{"date", "2021-01-02", true},
+		{"date", "2021-13-40", false},
+		{"time", "15:04:05Z", true},
+		{"time", "not-a-time", false},
+		{"duration", "P1Y2M3DT4H5M6S", true},
+		{"duration", "1Y2M3D", false},
+		{"email", "user@example.com", true},
+		{"email", "not-an-email", false},
+		{"hostname", "example.com", true},
+		{"hostname", "-bad-.com", false},
+		{"ipv4", "192.168.0.1", true},
+		{"ipv4", "::1", false},
+		{"ipv6", "::1", true},
+		{"ipv6", "192.168.0.1", false},
+		{"uri", "https://example.com/path", true},
+		{"uri", "not a uri", false},
+		{"uri-reference", "/relative/path", true},
+		{"uuid", "550e8400-e29b-41d4-a716-446655440000", true},
+		{"uuid", "not-a-uuid", false},
+		{"regex", "^[a-z]+$", true},
+		{"regex", "(unclosed", false},
+		{"json-pointer", "/a/b/c", true},
+		{"json-pointer", "no-leading-slash", false},
+		{"relative-json-pointer", "1/a/b", true},
+		{"relative-json-pointer", "/a/b", false},
+	}
+
+	for _, c := range cases {
+		checker, ok := lookupFormat(c.format)
+		if !ok {
+			t.Fatalf("format %q not registered", c.format)
+		}
+		if got := checker.IsFormat(c.value); got != c.want {
+			t.Errorf("format %q IsFormat(%q) = %v, want %v", c.format, c.value, got, c.want)
+		}
+	}
+}
+
+func TestFormatCheckerIgnoresNonString(t *testing.T) {
+	checker, ok := lookupFormat("email")
+	if !ok {
+		t.Fatalf("format %q not registered", "email")
+	}
+	if !checker.IsFormat(5) {
+		t.Errorf("expected a format checker to pass a non-string instance per annotation-vs-assertion semantics")
+	}
+}
+
+func TestRegisterAndUnregisterFormat(t *testing.T) {
+	RegisterFormat("always-false", FormatCheckerFunc(func(interface{}) bool { return false }))
+	defer Unregister("always-false")
+
+	checker, ok := lookupFormat("always-false")
+	if !ok || checker.IsFormat("anything") {
+		t.Fatalf("expected custom format to be registered and reject every string input")
+	}
+
+	Unregister("always-false")
+	if _, ok := lookupFormat("always-false"); ok {
+		t.Errorf("expected format to be gone after Unregister")
+	}
+}
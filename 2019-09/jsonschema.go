@@ -3,11 +3,6 @@ package jsonschema
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
-	"fmt"
-	"io/ioutil"
-	"net/http"
-	"net/url"
 )
 
 type Type string
@@ -76,14 +71,15 @@ type schema struct {
 
 	// Validation
 	// https://json-schema.org/draft/2019-09/json-schema-validation.html#rfc.section.6.1
-	Type  interface{} `json:"type,omitempty"` // Type or array of unique Types
-	Enum  []string    `json:"enum,omitempty"`
-	Const interface{} `json:"const,omitempty"`
+	Type  interface{}   `json:"type,omitempty"` // Type or array of unique Types
+	Enum  []interface{} `json:"enum,omitempty"` // compared against instances with jsonEqual, same as Const
+	Const interface{}   `json:"const,omitempty"`
 
 	// Arrays
 	// Additional subschema application keywords
 	// https://json-schema.org/draft/2019-09/json-schema-core.html#rfc.section.9.3.1
-	Items            interface{} `json:"items,omitempty"` // Schema or array of schemas
+	Items            interface{} `json:"items,omitempty"`       // Schema or array of schemas
+	PrefixItems      []*Schema   `json:"prefixItems,omitempty"` // 2020-12's tuple form; see Draft.Recognizes
 	AdditionalItems  *Schema     `json:"additionalItems,omitempty"`
 	UnevaluatedItems *Schema     `json:"unevaluatedItems,omitempty"`
 	Contains         *Schema     `json:"contains,omitempty"`
@@ -98,11 +94,11 @@ type schema struct {
 	// Objects
 	// Additional subschema application keywords
 	// https://json-schema.org/draft/2019-09/json-schema-core.html#rfc.section.9.3.2
-	Properties            map[string]*Schema
-	PatternProperties     map[string]*Schema // ECMA 262 regular expression dialect -> subschema
-	AdditionalProperties  *Schema
-	UnevaluatedProperties *Schema
-	PropertyNames         *Schema
+	Properties            map[string]*Schema `json:"properties,omitempty"`
+	PatternProperties     map[string]*Schema `json:"patternProperties,omitempty"` // ECMA 262 regular expression dialect -> subschema
+	AdditionalProperties  *Schema            `json:"additionalProperties,omitempty"`
+	UnevaluatedProperties *Schema            `json:"unevaluatedProperties,omitempty"`
+	PropertyNames         *Schema            `json:"propertyNames,omitempty"`
 	// Validation
 	// https://json-schema.org/draft/2019-09/json-schema-validation.html#rfc.section.6.5
 	MaxProperties     *uint64             `json:"maxProperties,omitempty"`
@@ -128,6 +124,9 @@ type schema struct {
 	Format    *string `json:"format,omitempty"`  // https://json-schema.org/draft/2019-09/json-schema-validation.html#rfc.section.7
 
 	baseURI string
+	// draft is the keyword table validate.go gates draft-specific keywords against. Set from
+	// this schema's own "$schema", or propagated from its parent document by propagateDraft.
+	draft Draft
 }
 
 // AsBool returns the boolean schema value, if it is a boolean schema value.
@@ -147,49 +146,28 @@ func (s *Schema) UnmarshalJSON(data []byte) error {
 		b := true
 		s.bool = &b
 	} else {
+		draft := DetectDraft(data)
+		normalized, err := normalize(draft, data)
+		if err != nil {
+			return err
+		}
 		s.schema = &schema{}
-		if err := json.Unmarshal(data, s.schema); err != nil {
+		if err := json.Unmarshal(normalized, s.schema); err != nil {
 			return err
 		}
+		propagateDraft(s, draft)
 	}
 	return nil
 }
 
-func FromURI(uri string) (*Schema, error) {
-	u, err := url.Parse(uri)
-	if err != nil {
-		return nil, err
-	}
-	s := &Schema{}
-	switch u.Scheme {
-	case "file":
-		bs, err := ioutil.ReadFile(u.Path)
-		if err != nil {
-			return nil, err
-		}
-		err = json.Unmarshal(bs, s)
-		if err != nil {
-			return nil, err
-		}
-	case "http", "https":
-		resp, err := http.Get(u.String())
-		if err != nil {
-			return nil, err
-		}
-		if resp.StatusCode != 200 {
-			return nil, errors.New("HTTP(S) URI returned a non-200 response")
-		}
-		defer func() { _ = resp.Body.Close() }()
-		bs, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		err = json.Unmarshal(bs, s)
-		if err != nil {
-			return nil, err
+// MarshalJSON renders s back to a spec-compliant document: `true`/`false` for a boolean
+// schema, or the underlying schema object otherwise.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if b, ok := s.AsBool(); ok {
+		if b {
+			return []byte("true"), nil
 		}
-	default:
-		return nil, fmt.Errorf("unsupported URI scheme: %q", u.Scheme)
+		return []byte("false"), nil
 	}
-	return s, nil
+	return json.Marshal(s.schema)
 }
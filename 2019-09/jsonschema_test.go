@@ -0,0 +1,56 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSchemaRoundTrip(t *testing.T) {
+	cases := []string{
+		`true`,
+		`false`,
+		`{"type":"string","minLength":1,"maxLength":10}`,
+		`{
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			},
+			"patternProperties": {
+				"^x-": {"type": "string"}
+			},
+			"required": ["name"],
+			"additionalProperties": false
+		}`,
+		`{
+			"anyOf": [
+				{"type": "string"},
+				{"type": "integer"}
+			],
+			"unevaluatedProperties": {"type": "boolean"}
+		}`,
+	}
+
+	for _, in := range cases {
+		var s Schema
+		if err := json.Unmarshal([]byte(in), &s); err != nil {
+			t.Fatalf("unmarshal %s: %v", in, err)
+		}
+		out, err := json.Marshal(&s)
+		if err != nil {
+			t.Fatalf("marshal %s: %v", in, err)
+		}
+
+		var wantAny, gotAny interface{}
+		if err := json.Unmarshal([]byte(in), &wantAny); err != nil {
+			t.Fatalf("unmarshal want %s: %v", in, err)
+		}
+		if err := json.Unmarshal(out, &gotAny); err != nil {
+			t.Fatalf("unmarshal got %s: %v", out, err)
+		}
+		if !reflect.DeepEqual(wantAny, gotAny) {
+			t.Errorf("round-trip mismatch for %s:\n got: %s\nwant: %s", in, out, in)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// OutputFormat selects the shape of an Output, per the structured output formats defined in
+// https://json-schema.org/draft/2019-09/json-schema-core.html#rfc.section.10.4.
+type OutputFormat int
+
+const (
+	// Flag reports only whether the instance is valid.
+	Flag OutputFormat = iota
+	// Basic reports a flat list of every failing keyword.
+	Basic
+	// Detailed reports failures as a tree that mirrors the schema's applicator structure,
+	// omitting branches that passed.
+	Detailed
+	// Verbose reports the full tree, including branches that passed.
+	Verbose
+)
+
+// Output is a validation result rendered in one of the four standard structured output
+// formats. It is produced by Schema.ValidateOutput and is safe to json.Marshal directly.
+type Output struct {
+	Valid  bool           `json:"valid"`
+	Errors []*OutputUnit  `json:"errors,omitempty"`
+	format OutputFormat
+}
+
+// OutputUnit is a single node of a structured output tree: one schema location's outcome
+// against one instance location.
+type OutputUnit struct {
+	KeywordLocation         string        `json:"keywordLocation"`
+	AbsoluteKeywordLocation string        `json:"absoluteKeywordLocation,omitempty"`
+	InstanceLocation        string        `json:"instanceLocation"`
+	Valid                   bool          `json:"valid"`
+	Error                   string        `json:"error,omitempty"`
+	Errors                  []*OutputUnit `json:"errors,omitempty"`
+	// Annotations holds the values produced by this node's annotation-bearing keywords, keyed
+	// by keyword name, e.g. {"properties": ["name", "age"]} for the property names "properties"
+	// evaluated at this node.
+	Annotations map[string]interface{} `json:"annotations,omitempty"`
+}
+
+// ValidateOutput validates instance against s, like Validate, but renders the result in the
+// requested structured OutputFormat instead of a flat Result.
+func (s *Schema) ValidateOutput(instance interface{}, format OutputFormat) (*Output, error) {
+	res, err := s.Validate(instance)
+	if err != nil {
+		return nil, err
+	}
+	return newOutput(s, instance, res, format), nil
+}
+
+func newOutput(s *Schema, instance interface{}, res *Result, format OutputFormat) *Output {
+	out := &Output{Valid: res.Valid(), format: format}
+	if format == Flag {
+		return out
+	}
+	if format == Basic {
+		if res.Valid() {
+			return out
+		}
+		units := make([]*OutputUnit, len(res.Errors))
+		for i, e := range res.Errors {
+			units[i] = &OutputUnit{
+				KeywordLocation:  e.SchemaLocation,
+				InstanceLocation: e.InstanceLocation,
+				Valid:            false,
+				Error:            e.Message,
+			}
+		}
+		out.Errors = units
+		return out
+	}
+
+	// Detailed and Verbose both need the real applicator tree, not just the flat failures, so
+	// that Detailed can prune passing branches instead of approximating them away, and Verbose
+	// can show them at all.
+	w := &outputWalker{errs: res.Errors, includePassing: format == Verbose, resolver: &localResolver{root: s}}
+	root := w.build(s, instance, "", "")
+	out.Errors = root.Errors
+	return out
+}
+
+// outputWalker builds a Detailed/Verbose output tree by re-walking the schema/instance pair in
+// lockstep with validate.go's own traversal, looking up each node's pass/fail outcome in the flat
+// errors validate.go already collected rather than re-deriving it.
+type outputWalker struct {
+	errs           []*ValidationError
+	includePassing bool
+	resolver       refResolver
+}
+
+// failsAt reports whether errs contains a failure for exactly this keyword's location.
+func (w *outputWalker) failsAt(schemaLoc, instanceLoc string) (string, bool) {
+	for _, e := range w.errs {
+		if e.SchemaLocation == schemaLoc && e.InstanceLocation == instanceLoc {
+			return e.Message, true
+		}
+	}
+	return "", false
+}
+
+// failsUnder reports whether errs contains any failure at or under schemaLoc for instanceLoc,
+// i.e. whether applying the schema at schemaLoc to instanceLoc failed anywhere within it.
+func (w *outputWalker) failsUnder(schemaLoc, instanceLoc string) bool {
+	for _, e := range w.errs {
+		if e.InstanceLocation != instanceLoc && !strings.HasPrefix(e.InstanceLocation, instanceLoc+"/") {
+			continue
+		}
+		if e.SchemaLocation == schemaLoc || strings.HasPrefix(e.SchemaLocation, schemaLoc+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// build returns the output node for s applied to instance at instanceLoc/schemaLoc, with Errors
+// populated by every child keyword node that's relevant: all of them for Verbose, only the
+// failing ones for Detailed.
+func (w *outputWalker) build(s *Schema, instance interface{}, instanceLoc, schemaLoc string) *OutputUnit {
+	node := &OutputUnit{KeywordLocation: schemaLoc, InstanceLocation: instanceLoc, Valid: true}
+	if s == nil {
+		return node
+	}
+	if b, ok := s.AsBool(); ok {
+		node.Valid = b
+		return node
+	}
+	sc := s.schema
+	if sc == nil {
+		return node
+	}
+	if sc.ID != nil {
+		node.AbsoluteKeywordLocation = *sc.ID + "#" + schemaLoc
+	}
+
+	var children []*OutputUnit
+	add := func(child *OutputUnit) {
+		if child == nil {
+			return
+		}
+		if w.includePassing || !child.Valid {
+			children = append(children, child)
+		}
+	}
+
+	add(w.leaf(schemaLoc+"/type", instanceLoc, sc.Type != nil))
+	add(w.leaf(schemaLoc+"/const", instanceLoc, sc.Const != nil && sc.draft.Recognizes("const")))
+	add(w.leaf(schemaLoc+"/enum", instanceLoc, len(sc.Enum) > 0))
+
+	switch v := instance.(type) {
+	case float64:
+		add(w.leaf(schemaLoc+"/multipleOf", instanceLoc, sc.MultipleOf != nil))
+		add(w.leaf(schemaLoc+"/maximum", instanceLoc, sc.Maximum != nil))
+		add(w.leaf(schemaLoc+"/exclusiveMaximum", instanceLoc, sc.ExclusiveMaximum != nil))
+		add(w.leaf(schemaLoc+"/minimum", instanceLoc, sc.Minimum != nil))
+		add(w.leaf(schemaLoc+"/exclusiveMinimum", instanceLoc, sc.ExclusiveMinimum != nil))
+	case string:
+		add(w.leaf(schemaLoc+"/maxLength", instanceLoc, sc.MaxLength != nil))
+		add(w.leaf(schemaLoc+"/minLength", instanceLoc, sc.MinLength != nil))
+		add(w.leaf(schemaLoc+"/pattern", instanceLoc, sc.Pattern != nil))
+		add(w.leaf(schemaLoc+"/format", instanceLoc, sc.Format != nil))
+	case []interface{}:
+		w.buildArray(sc, v, instanceLoc, schemaLoc, add)
+	case map[string]interface{}:
+		if names := w.buildObject(sc, v, instanceLoc, schemaLoc, add); len(names) > 0 {
+			node.Annotations = map[string]interface{}{"properties": names}
+		}
+	}
+
+	w.buildApplicators(sc, instance, instanceLoc, schemaLoc, add)
+
+	node.Errors = children
+	node.Valid = !w.failsUnder(schemaLoc, instanceLoc)
+	return node
+}
+
+// leaf reports the outcome of a keyword with no nested schema (e.g. "type", "minLength"),
+// looking its exact location up in the flat error list. present is false when the keyword wasn't
+// in the schema at all, in which case leaf reports nothing.
+func (w *outputWalker) leaf(schemaLoc, instanceLoc string, present bool) *OutputUnit {
+	if !present {
+		return nil
+	}
+	msg, failed := w.failsAt(schemaLoc, instanceLoc)
+	return &OutputUnit{
+		KeywordLocation:  schemaLoc,
+		InstanceLocation: instanceLoc,
+		Valid:            !failed,
+		Error:            msg,
+	}
+}
+
+func (w *outputWalker) buildArray(sc *schema, v []interface{}, instanceLoc, schemaLoc string, add func(*OutputUnit)) {
+	add(w.leaf(schemaLoc+"/maxItems", instanceLoc, sc.MaxItems != nil))
+	add(w.leaf(schemaLoc+"/minItems", instanceLoc, sc.MinItems != nil))
+	add(w.leaf(schemaLoc+"/uniqueItems", instanceLoc, sc.UniqueItems != nil))
+
+	switch {
+	case len(sc.PrefixItems) > 0 && sc.draft.Recognizes("prefixItems"):
+		for i, sub := range sc.PrefixItems {
+			if i >= len(v) {
+				break
+			}
+			add(w.build(sub, v[i], ptrAppendIndex(instanceLoc, i), schemaLoc+"/prefixItems/"+strconv.Itoa(i)))
+		}
+		if sc.Items != nil {
+			sub := schemaFromRaw(sc.Items)
+			for i := len(sc.PrefixItems); i < len(v); i++ {
+				add(w.build(sub, v[i], ptrAppendIndex(instanceLoc, i), schemaLoc+"/items"))
+			}
+		}
+	default:
+		if tuple, ok := sc.Items.([]interface{}); ok {
+			for i, raw := range tuple {
+				if i >= len(v) {
+					break
+				}
+				add(w.build(schemaFromRaw(raw), v[i], ptrAppendIndex(instanceLoc, i), schemaLoc+"/items/"+strconv.Itoa(i)))
+			}
+			if sc.AdditionalItems != nil {
+				for i := len(tuple); i < len(v); i++ {
+					add(w.build(sc.AdditionalItems, v[i], ptrAppendIndex(instanceLoc, i), schemaLoc+"/additionalItems"))
+				}
+			}
+		} else if sc.Items != nil {
+			sub := schemaFromRaw(sc.Items)
+			for i, item := range v {
+				add(w.build(sub, item, ptrAppendIndex(instanceLoc, i), schemaLoc+"/items"))
+			}
+		}
+	}
+
+	if sc.Contains != nil && sc.draft.Recognizes("contains") {
+		for i, item := range v {
+			add(w.build(sc.Contains, item, ptrAppendIndex(instanceLoc, i), schemaLoc+"/contains"))
+		}
+		add(w.leaf(schemaLoc+"/contains", instanceLoc, true))
+	}
+	if sc.UnevaluatedItems != nil && sc.draft.Recognizes("unevaluatedItems") {
+		add(w.leaf(schemaLoc+"/unevaluatedItems", instanceLoc, true))
+	}
+}
+
+// buildObject adds this node's object-keyword children and returns the property names evaluated
+// by "properties"/"patternProperties", for the caller to record as an annotation.
+func (w *outputWalker) buildObject(sc *schema, v map[string]interface{}, instanceLoc, schemaLoc string, add func(*OutputUnit)) []string {
+	add(w.leaf(schemaLoc+"/maxProperties", instanceLoc, sc.MaxProperties != nil))
+	add(w.leaf(schemaLoc+"/minProperties", instanceLoc, sc.MinProperties != nil))
+	add(w.leaf(schemaLoc+"/required", instanceLoc, len(sc.Required) > 0))
+	add(w.leaf(schemaLoc+"/dependentRequired", instanceLoc, len(sc.DependentRequired) > 0 && sc.draft.Recognizes("dependentRequired")))
+
+	evaluated := map[string]bool{}
+	for name, val := range v {
+		if sub, ok := sc.Properties[name]; ok {
+			add(w.build(sub, val, ptrAppendName(instanceLoc, name), schemaLoc+"/properties/"+jsonPointerEscape(name)))
+			evaluated[name] = true
+		}
+		for pattern, sub := range sc.PatternProperties {
+			re, err := regexp.Compile(pattern)
+			if err != nil || !re.MatchString(name) {
+				continue
+			}
+			add(w.build(sub, val, ptrAppendName(instanceLoc, name), schemaLoc+"/patternProperties/"+jsonPointerEscape(pattern)))
+			evaluated[name] = true
+		}
+	}
+	if sc.AdditionalProperties != nil {
+		for name, val := range v {
+			if evaluated[name] {
+				continue
+			}
+			add(w.build(sc.AdditionalProperties, val, ptrAppendName(instanceLoc, name), schemaLoc+"/additionalProperties"))
+		}
+	}
+	if sc.PropertyNames != nil && sc.draft.Recognizes("propertyNames") {
+		for name := range v {
+			add(w.build(sc.PropertyNames, name, ptrAppendName(instanceLoc, name), schemaLoc+"/propertyNames"))
+		}
+	}
+	if sc.UnevaluatedProperties != nil && sc.draft.Recognizes("unevaluatedProperties") {
+		add(w.leaf(schemaLoc+"/unevaluatedProperties", instanceLoc, true))
+	}
+
+	names := make([]string, 0, len(evaluated))
+	for name := range evaluated {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (w *outputWalker) buildApplicators(sc *schema, instance interface{}, instanceLoc, schemaLoc string, add func(*OutputUnit)) {
+	for i, sub := range sc.AllOf {
+		add(w.build(sub, instance, instanceLoc, schemaLoc+"/allOf/"+strconv.Itoa(i)))
+	}
+	for i, sub := range sc.AnyOf {
+		add(w.build(sub, instance, instanceLoc, schemaLoc+"/anyOf/"+strconv.Itoa(i)))
+	}
+	for i, sub := range sc.OneOf {
+		add(w.build(sub, instance, instanceLoc, schemaLoc+"/oneOf/"+strconv.Itoa(i)))
+	}
+	if sc.Not != nil {
+		add(w.build(sc.Not, instance, instanceLoc, schemaLoc+"/not"))
+	}
+	if sc.If != nil && sc.draft.Recognizes("if") {
+		add(w.build(sc.If, instance, instanceLoc, schemaLoc+"/if"))
+		// validate.go only evaluates then/else for the branch "if" actually took; mirror that
+		// here instead of always building both, or Verbose would show a passing node for a
+		// branch that was never evaluated. validate.go judges "if" in a separate Result, so its
+		// failures never reach the flat error list; re-validate it here to learn the outcome.
+		ifRes := &Result{}
+		(&validationCtx{result: ifRes, resolver: w.resolver}).validate(sc.If, instance, instanceLoc, schemaLoc+"/if")
+		if ifRes.Valid() {
+			if sc.Then != nil {
+				add(w.build(sc.Then, instance, instanceLoc, schemaLoc+"/then"))
+			}
+		} else if sc.Else != nil {
+			add(w.build(sc.Else, instance, instanceLoc, schemaLoc+"/else"))
+		}
+	}
+	if obj, ok := instance.(map[string]interface{}); ok {
+		for prop, sub := range sc.DependentSchemas {
+			if _, present := obj[prop]; !present {
+				continue
+			}
+			add(w.build(sub, instance, instanceLoc, schemaLoc+"/dependentSchemas/"+jsonPointerEscape(prop)))
+		}
+	}
+	if sc.Ref != nil {
+		add(w.buildRef(*sc.Ref, schemaLoc+"/$ref", instance, instanceLoc))
+	}
+	if sc.RecursiveRef != nil && sc.draft.Recognizes("$recursiveRef") {
+		add(w.buildRef("#", schemaLoc+"/$recursiveRef", instance, instanceLoc))
+	}
+}
+
+func (w *outputWalker) buildRef(ref, schemaLoc string, instance interface{}, instanceLoc string) *OutputUnit {
+	if w.resolver == nil {
+		return w.leaf(schemaLoc, instanceLoc, true)
+	}
+	target, err := w.resolver.Resolve(ref)
+	if err != nil {
+		return w.leaf(schemaLoc, instanceLoc, true)
+	}
+	return w.build(target, instance, instanceLoc, schemaLoc)
+}
+
+// MarshalJSON renders o per its OutputFormat: Flag emits only {"valid": ...}.
+func (o *Output) MarshalJSON() ([]byte, error) {
+	if o.format == Flag {
+		return json.Marshal(struct {
+			Valid bool `json:"valid"`
+		}{o.Valid})
+	}
+	type alias Output
+	return json.Marshal((*alias)(o))
+}
@@ -0,0 +1,92 @@
+package jsonschema
+
+import "testing"
+
+func TestValidateOutputBasic(t *testing.T) {
+	s := mustUnmarshalSchema(t, `{
+		"properties": {"name": {"type": "string"}}
+	}`)
+
+	out, err := s.ValidateOutput(mustUnmarshalInstance(t, `{"name": 5}`), Basic)
+	if err != nil {
+		t.Fatalf("ValidateOutput: %v", err)
+	}
+	if out.Valid {
+		t.Fatalf("expected invalid")
+	}
+	if len(out.Errors) != 1 || out.Errors[0].KeywordLocation != "/properties/name/type" {
+		t.Fatalf("expected a single /properties/name/type error, got %+v", out.Errors)
+	}
+}
+
+func TestValidateOutputVerboseIncludesPassingBranches(t *testing.T) {
+	s := mustUnmarshalSchema(t, `{
+		"properties": {"name": {"type": "string"}}
+	}`)
+
+	out, err := s.ValidateOutput(mustUnmarshalInstance(t, `{"name": "ok"}`), Verbose)
+	if err != nil {
+		t.Fatalf("ValidateOutput: %v", err)
+	}
+	if !out.Valid {
+		t.Fatalf("expected valid")
+	}
+
+	var found bool
+	for _, u := range out.Errors {
+		if u.KeywordLocation == "/properties/name" && u.Valid {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected Verbose to include the passing /properties/name branch, got %+v", out.Errors)
+	}
+}
+
+func TestValidateOutputIfThenElseOnlyIncludesTakenBranch(t *testing.T) {
+	s := mustUnmarshalSchema(t, `{
+		"if": {"type": "string"},
+		"then": {"minLength": 3},
+		"else": {"minimum": 0}
+	}`)
+
+	out, err := s.ValidateOutput(mustUnmarshalInstance(t, `"ab"`), Verbose)
+	if err != nil {
+		t.Fatalf("ValidateOutput: %v", err)
+	}
+
+	var sawThen, sawElse bool
+	for _, u := range out.Errors {
+		switch u.KeywordLocation {
+		case "/then":
+			sawThen = true
+		case "/else":
+			sawElse = true
+		}
+	}
+	if !sawThen {
+		t.Errorf("expected the taken /then branch to appear, got %+v", out.Errors)
+	}
+	if sawElse {
+		t.Errorf("expected the untaken /else branch to be omitted, got %+v", out.Errors)
+	}
+}
+
+func TestValidateOutputDetailedPrunesPassingBranches(t *testing.T) {
+	s := mustUnmarshalSchema(t, `{
+		"properties": {
+			"name": {"type": "string"},
+			"age": {"type": "integer"}
+		}
+	}`)
+
+	out, err := s.ValidateOutput(mustUnmarshalInstance(t, `{"name": 5, "age": 1}`), Detailed)
+	if err != nil {
+		t.Fatalf("ValidateOutput: %v", err)
+	}
+	for _, u := range out.Errors {
+		if u.KeywordLocation == "/properties/age" {
+			t.Fatalf("expected Detailed to prune the passing /properties/age branch, got %+v", out.Errors)
+		}
+	}
+}
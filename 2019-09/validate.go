@@ -0,0 +1,791 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ValidationError describes a single keyword that failed to validate against an instance.
+type ValidationError struct {
+	// InstanceLocation is a JSON Pointer (RFC 6901) to the offending value in the instance.
+	InstanceLocation string
+	// SchemaLocation is a JSON Pointer to the keyword within the schema that produced the error.
+	SchemaLocation string
+	// Keyword is the failing schema keyword, e.g. "minLength" or "required".
+	Keyword string
+	// Message is a human-readable description of the failure.
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.InstanceLocation, e.Message)
+}
+
+// Result is the outcome of validating an instance against a Schema.
+type Result struct {
+	Errors []*ValidationError
+}
+
+// Valid reports whether the instance satisfied the schema.
+func (r *Result) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+func (r *Result) addError(instanceLoc, schemaLoc, keyword, format string, args ...interface{}) {
+	r.Errors = append(r.Errors, &ValidationError{
+		InstanceLocation: instanceLoc,
+		SchemaLocation:   schemaLoc,
+		Keyword:          keyword,
+		Message:          fmt.Sprintf(format, args...),
+	})
+}
+
+// ValidateBytes unmarshals instance as JSON and validates it against s.
+func (s *Schema) ValidateBytes(instance []byte) (*Result, error) {
+	var v interface{}
+	if err := json.Unmarshal(instance, &v); err != nil {
+		return nil, err
+	}
+	return s.Validate(v)
+}
+
+// Validate checks instance (as produced by json.Unmarshal into interface{}) against s and
+// returns a Result describing every keyword that failed. The returned error is always nil:
+// a malformed pattern or an unresolvable $ref is reported as a failure in the Result.
+//
+// $ref/$recursiveRef are resolved as same-document "#/..." JSON Pointer fragments; a schema
+// that references another document needs to be compiled first via NewCompiler().Compile, and
+// validated through the resulting CompiledSchema's Validate method instead.
+func (s *Schema) Validate(instance interface{}) (*Result, error) {
+	res := &Result{}
+	ctx := &validationCtx{result: res, resolver: &localResolver{root: s}, dynamicScope: []*Schema{s}}
+	ctx.validate(s, instance, "", "")
+	return res, nil
+}
+
+type validationCtx struct {
+	result   *Result
+	resolver refResolver
+	// dynamicScope is the stack of schema resources entered so far along the current
+	// validation path, outermost first, starting with the schema Validate was originally
+	// called with. $ref/$recursiveRef push the resource they redirect into; see validateScoped
+	// and recursiveRefTarget.
+	dynamicScope []*Schema
+}
+
+// branch returns a validationCtx that shares ctx's resolver and dynamicScope but reports into
+// res instead of ctx.result. Used by anyOf/oneOf/not/if/contains to validate a sub-schema
+// in isolation - so its own validity can be judged without polluting the parent's errors - while
+// keeping $ref/$recursiveRef resolution and $recursiveAnchor dynamic-scope tracking working
+// inside the branch.
+func (ctx *validationCtx) branch(res *Result) *validationCtx {
+	return &validationCtx{result: res, resolver: ctx.resolver, dynamicScope: ctx.dynamicScope}
+}
+
+// validateScoped validates target as a new dynamic-scope frame, pushing it onto ctx.dynamicScope
+// for the duration of the call. $ref and $recursiveRef both redirect validation into a (possibly
+// different) schema resource, and $recursiveRef's own resolution depends on which resources with
+// "$recursiveAnchor": true have been entered this way along the current path - see
+// recursiveRefTarget.
+func (ctx *validationCtx) validateScoped(target *Schema, instance interface{}, instanceLoc, schemaLoc string) *annotations {
+	ctx.dynamicScope = append(ctx.dynamicScope, target)
+	ann := ctx.validate(target, instance, instanceLoc, schemaLoc)
+	ctx.dynamicScope = ctx.dynamicScope[:len(ctx.dynamicScope)-1]
+	return ann
+}
+
+// recursiveRefTarget resolves a "$recursiveRef": "#" keyword per the dynamic-scope rule: search
+// ctx.dynamicScope from the outermost frame inward for the first schema resource that opted in
+// via "$recursiveAnchor": true, and recurse into that resource itself. If none opted in,
+// $recursiveRef resolves "#" through ctx.resolver, i.e. to the root schema being validated
+// (Schema.Validate's receiver, or CompiledSchema.Root).
+func (ctx *validationCtx) recursiveRefTarget() (*Schema, error) {
+	for _, frame := range ctx.dynamicScope {
+		if sc := frame.schema; sc != nil && sc.RecursiveAnchor != nil && *sc.RecursiveAnchor {
+			return frame, nil
+		}
+	}
+	return ctx.resolver.Resolve("#")
+}
+
+// refResolver resolves the literal value of a $ref or $recursiveRef keyword to the Schema it
+// targets. Schema.Validate uses localResolver, which only understands same-document "#/..."
+// pointers; CompiledSchema.Validate uses compiledResolver, which can also cross into other
+// documents via the Compiler's $id/$anchor/$ref index.
+type refResolver interface {
+	Resolve(ref string) (*Schema, error)
+}
+
+// localResolver resolves $ref/$recursiveRef values that are JSON Pointer fragments into the
+// same document being validated (e.g. "#/$defs/positiveInt"), which is by far the most common
+// shape of $ref in a self-contained schema.
+type localResolver struct {
+	root *Schema
+}
+
+func (r *localResolver) Resolve(ref string) (*Schema, error) {
+	if ref == "#" {
+		return r.root, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q: Schema.Validate only resolves same-document \"#/...\" references; compile the schema with a Compiler and validate through the resulting CompiledSchema for cross-document refs", ref)
+	}
+	return resolvePointer(r.root, strings.TrimPrefix(ref, "#"))
+}
+
+// resolvePointer descends from root along pointer (a JSON Pointer, "/"-separated and ~0/~1
+// escaped), following exactly the keywords a Compiler would walk when indexing $defs,
+// properties, and the other schema-bearing containers. Container keywords ($defs, definitions,
+// properties, patternProperties, dependentSchemas, allOf/anyOf/oneOf, and tuple-form items)
+// consume two tokens at a time (the keyword, then the map key or array index); the rest consume
+// one.
+func resolvePointer(root *Schema, pointer string) (*Schema, error) {
+	toks := splitPointer(pointer)
+	cur := root
+	for i := 0; i < len(toks); i++ {
+		if cur == nil || cur.schema == nil {
+			return nil, fmt.Errorf("resolving %q: %q is not a schema object", pointer, toks[i])
+		}
+		sc := cur.schema
+		tok := toks[i]
+		switch tok {
+		case "$defs", "definitions", "properties", "patternProperties", "dependentSchemas":
+			i++
+			if i >= len(toks) {
+				return nil, fmt.Errorf("resolving %q: %q has no key", pointer, tok)
+			}
+			key := toks[i]
+			m := map[string]map[string]*Schema{
+				"$defs":             sc.Defs,
+				"definitions":       sc.Definitions,
+				"properties":        sc.Properties,
+				"patternProperties": sc.PatternProperties,
+				"dependentSchemas":  sc.DependentSchemas,
+			}[tok]
+			next, ok := m[key]
+			if !ok {
+				return nil, fmt.Errorf("resolving %q: no %s entry %q", pointer, tok, key)
+			}
+			cur = next
+		case "allOf", "anyOf", "oneOf":
+			i++
+			if i >= len(toks) {
+				return nil, fmt.Errorf("resolving %q: %q has no index", pointer, tok)
+			}
+			idx, err := strconv.Atoi(toks[i])
+			if err != nil {
+				return nil, fmt.Errorf("resolving %q: %s index %q: %w", pointer, tok, toks[i], err)
+			}
+			list := map[string][]*Schema{"allOf": sc.AllOf, "anyOf": sc.AnyOf, "oneOf": sc.OneOf}[tok]
+			if idx < 0 || idx >= len(list) {
+				return nil, fmt.Errorf("resolving %q: %s index %d out of range", pointer, tok, idx)
+			}
+			cur = list[idx]
+		case "items":
+			if tuple, ok := sc.Items.([]interface{}); ok && i+1 < len(toks) {
+				idx, err := strconv.Atoi(toks[i+1])
+				if err == nil {
+					if idx < 0 || idx >= len(tuple) {
+						return nil, fmt.Errorf("resolving %q: items index %d out of range", pointer, idx)
+					}
+					cur = schemaFromRaw(tuple[idx])
+					i++
+					continue
+				}
+			}
+			cur = schemaFromRaw(sc.Items)
+		case "additionalItems":
+			cur = sc.AdditionalItems
+		case "unevaluatedItems":
+			cur = sc.UnevaluatedItems
+		case "contains":
+			cur = sc.Contains
+		case "additionalProperties":
+			cur = sc.AdditionalProperties
+		case "unevaluatedProperties":
+			cur = sc.UnevaluatedProperties
+		case "propertyNames":
+			cur = sc.PropertyNames
+		case "not":
+			cur = sc.Not
+		case "if":
+			cur = sc.If
+		case "then":
+			cur = sc.Then
+		case "else":
+			cur = sc.Else
+		default:
+			return nil, fmt.Errorf("resolving %q: unsupported $ref path segment %q", pointer, tok)
+		}
+	}
+	if cur == nil {
+		return nil, fmt.Errorf("resolving %q: reference target is not present", pointer)
+	}
+	return cur, nil
+}
+
+func splitPointer(pointer string) []string {
+	pointer = strings.TrimPrefix(pointer, "/")
+	if pointer == "" {
+		return nil
+	}
+	parts := strings.Split(pointer, "/")
+	for i, p := range parts {
+		parts[i] = jsonPointerUnescape(p)
+	}
+	return parts
+}
+
+func jsonPointerUnescape(s string) string {
+	s = strings.ReplaceAll(s, "~1", "/")
+	s = strings.ReplaceAll(s, "~0", "~")
+	return s
+}
+
+// annotations records which object properties / array items were "evaluated" by sibling
+// and in-place applicator keywords, so unevaluatedProperties/unevaluatedItems can consume them.
+type annotations struct {
+	evaluatedProps  map[string]bool
+	evaluatedItems  int // number of items, from the start, covered by items/prefixItems
+	allItemsCovered bool
+}
+
+func mergeAnnotations(dst *annotations, src *annotations) {
+	if src == nil || dst == nil {
+		return
+	}
+	for k := range src.evaluatedProps {
+		dst.evaluatedProps[k] = true
+	}
+	if src.allItemsCovered {
+		dst.allItemsCovered = true
+	}
+	if src.evaluatedItems > dst.evaluatedItems {
+		dst.evaluatedItems = src.evaluatedItems
+	}
+}
+
+// validate evaluates schema against instance at the given instance/schema JSON Pointer
+// locations, appending any failures to ctx.result. It returns the annotations produced by
+// evaluating this schema, for consumption by unevaluatedProperties/unevaluatedItems.
+func (ctx *validationCtx) validate(s *Schema, instance interface{}, instanceLoc, schemaLoc string) *annotations {
+	ann := &annotations{evaluatedProps: map[string]bool{}}
+
+	if s == nil {
+		return ann
+	}
+	if b, ok := s.AsBool(); ok {
+		if !b {
+			ctx.result.addError(instanceLoc, schemaLoc, "", "instance is not allowed: schema is `false`")
+		}
+		return ann
+	}
+	sc := s.schema
+	if sc == nil {
+		return ann
+	}
+
+	if sc.Const != nil && sc.draft.Recognizes("const") {
+		if !jsonEqual(sc.Const, instance) {
+			ctx.result.addError(instanceLoc, schemaLoc+"/const", "const", "must be equal to the constant defined in the schema")
+		}
+	}
+
+	if len(sc.Enum) > 0 {
+		match := false
+		for _, e := range sc.Enum {
+			if jsonEqual(e, instance) {
+				match = true
+				break
+			}
+		}
+		if !match {
+			ctx.result.addError(instanceLoc, schemaLoc+"/enum", "enum", "must be one of the enumerated values")
+		}
+	}
+
+	ctx.validateType(sc, instance, instanceLoc, schemaLoc)
+
+	switch v := instance.(type) {
+	case float64:
+		ctx.validateNumeric(sc, v, instanceLoc, schemaLoc)
+	case string:
+		ctx.validateString(sc, v, instanceLoc, schemaLoc)
+	case []interface{}:
+		ctx.validateArray(sc, v, instanceLoc, schemaLoc, ann)
+	case map[string]interface{}:
+		ctx.validateObject(sc, v, instanceLoc, schemaLoc, ann)
+	}
+
+	ctx.validateApplicators(sc, instance, instanceLoc, schemaLoc, ann)
+
+	// unevaluatedProperties/unevaluatedItems run last, after every other in-place applicator
+	// (allOf/anyOf/oneOf/if-then-else/dependentSchemas/$ref) has had a chance to contribute to
+	// ann - a property or item they alone evaluated must still count as evaluated here.
+	switch v := instance.(type) {
+	case []interface{}:
+		ctx.validateUnevaluatedItems(sc, v, instanceLoc, schemaLoc, ann)
+	case map[string]interface{}:
+		ctx.validateUnevaluatedProperties(sc, v, instanceLoc, schemaLoc, ann)
+	}
+
+	return ann
+}
+
+func (ctx *validationCtx) validateType(sc *schema, instance interface{}, instanceLoc, schemaLoc string) {
+	if sc.Type == nil {
+		return
+	}
+	var want []Type
+	switch t := sc.Type.(type) {
+	case string:
+		want = []Type{Type(t)}
+	case []interface{}:
+		for _, e := range t {
+			if s, ok := e.(string); ok {
+				want = append(want, Type(s))
+			}
+		}
+	}
+	if len(want) == 0 {
+		return
+	}
+	got := instanceType(instance)
+	for _, t := range want {
+		if t == got {
+			return
+		}
+		// integer is a subset of number
+		if t == INTEGER && got == NUMBER {
+			if f, ok := instance.(float64); ok && f == math.Trunc(f) {
+				return
+			}
+		}
+	}
+	ctx.result.addError(instanceLoc, schemaLoc+"/type", "type", "must be of type %v, got %s", want, got)
+}
+
+func instanceType(instance interface{}) Type {
+	switch instance.(type) {
+	case nil:
+		return NULL
+	case bool:
+		return BOOLEAN
+	case float64:
+		return NUMBER
+	case string:
+		return STRING
+	case []interface{}:
+		return ARRAY
+	case map[string]interface{}:
+		return OBJECT
+	default:
+		return ""
+	}
+}
+
+func (ctx *validationCtx) validateNumeric(sc *schema, v float64, instanceLoc, schemaLoc string) {
+	if sc.MultipleOf != nil && *sc.MultipleOf != 0 {
+		q := v / *sc.MultipleOf
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			ctx.result.addError(instanceLoc, schemaLoc+"/multipleOf", "multipleOf", "must be a multiple of %v", *sc.MultipleOf)
+		}
+	}
+	if sc.Maximum != nil && v > *sc.Maximum {
+		ctx.result.addError(instanceLoc, schemaLoc+"/maximum", "maximum", "must be <= %v", *sc.Maximum)
+	}
+	if sc.ExclusiveMaximum != nil && v >= *sc.ExclusiveMaximum {
+		ctx.result.addError(instanceLoc, schemaLoc+"/exclusiveMaximum", "exclusiveMaximum", "must be < %v", *sc.ExclusiveMaximum)
+	}
+	if sc.Minimum != nil && v < *sc.Minimum {
+		ctx.result.addError(instanceLoc, schemaLoc+"/minimum", "minimum", "must be >= %v", *sc.Minimum)
+	}
+	if sc.ExclusiveMinimum != nil && v <= *sc.ExclusiveMinimum {
+		ctx.result.addError(instanceLoc, schemaLoc+"/exclusiveMinimum", "exclusiveMinimum", "must be > %v", *sc.ExclusiveMinimum)
+	}
+}
+
+func (ctx *validationCtx) validateString(sc *schema, v string, instanceLoc, schemaLoc string) {
+	length := uint64(len([]rune(v)))
+	if sc.MaxLength != nil && length > *sc.MaxLength {
+		ctx.result.addError(instanceLoc, schemaLoc+"/maxLength", "maxLength", "must be no more than %d characters", *sc.MaxLength)
+	}
+	if sc.MinLength != nil && length < *sc.MinLength {
+		ctx.result.addError(instanceLoc, schemaLoc+"/minLength", "minLength", "must be at least %d characters", *sc.MinLength)
+	}
+	if sc.Pattern != nil {
+		re, err := regexp.Compile(*sc.Pattern)
+		if err != nil {
+			ctx.result.addError(instanceLoc, schemaLoc+"/pattern", "pattern", "invalid pattern %q: %s", *sc.Pattern, err)
+		} else if !re.MatchString(v) {
+			ctx.result.addError(instanceLoc, schemaLoc+"/pattern", "pattern", "must match pattern %q", *sc.Pattern)
+		}
+	}
+	if sc.Format != nil {
+		if checker, ok := lookupFormat(*sc.Format); ok && !checker.IsFormat(v) {
+			ctx.result.addError(instanceLoc, schemaLoc+"/format", "format", "must match format %q", *sc.Format)
+		}
+	}
+}
+
+func (ctx *validationCtx) validateArray(sc *schema, v []interface{}, instanceLoc, schemaLoc string, ann *annotations) {
+	if sc.MaxItems != nil && uint64(len(v)) > *sc.MaxItems {
+		ctx.result.addError(instanceLoc, schemaLoc+"/maxItems", "maxItems", "must have no more than %d items", *sc.MaxItems)
+	}
+	if sc.MinItems != nil && uint64(len(v)) < *sc.MinItems {
+		ctx.result.addError(instanceLoc, schemaLoc+"/minItems", "minItems", "must have at least %d items", *sc.MinItems)
+	}
+	if sc.UniqueItems != nil && *sc.UniqueItems {
+		for i := 0; i < len(v); i++ {
+			for j := i + 1; j < len(v); j++ {
+				if jsonEqual(v[i], v[j]) {
+					ctx.result.addError(instanceLoc, schemaLoc+"/uniqueItems", "uniqueItems", "items at index %d and %d must be unique", i, j)
+					break
+				}
+			}
+		}
+	}
+
+	start := 0
+	if len(sc.PrefixItems) > 0 && sc.draft.Recognizes("prefixItems") {
+		// 2020-12's tuple form: prefixItems gives one subschema per positional item, and items
+		// (a single schema, never a tuple, under this draft) covers whatever's left.
+		for i, sub := range sc.PrefixItems {
+			if i >= len(v) {
+				break
+			}
+			ctx.validate(sub, v[i], ptrAppendIndex(instanceLoc, i), fmt.Sprintf("%s/prefixItems/%d", schemaLoc, i))
+			start = i + 1
+		}
+		if itemSchema := schemaFromRaw(sc.Items); itemSchema != nil {
+			for i := start; i < len(v); i++ {
+				ctx.validate(itemSchema, v[i], ptrAppendIndex(instanceLoc, i), schemaLoc+"/items")
+			}
+			ann.allItemsCovered = true
+		} else {
+			ann.evaluatedItems = start
+		}
+	} else {
+		switch it := sc.Items.(type) {
+		case []interface{}:
+			// tuple validation: one subschema per positional item
+			for i, raw := range it {
+				if i >= len(v) {
+					break
+				}
+				sub := schemaFromRaw(raw)
+				ctx.validate(sub, v[i], ptrAppendIndex(instanceLoc, i), fmt.Sprintf("%s/items/%d", schemaLoc, i))
+				start = i + 1
+			}
+			if sc.AdditionalItems != nil {
+				for i := start; i < len(v); i++ {
+					ctx.validate(sc.AdditionalItems, v[i], ptrAppendIndex(instanceLoc, i), schemaLoc+"/additionalItems")
+				}
+				ann.allItemsCovered = true
+			} else {
+				ann.evaluatedItems = start
+			}
+		case map[string]interface{}, bool:
+			sub := schemaFromRaw(it)
+			for i, item := range v {
+				ctx.validate(sub, item, ptrAppendIndex(instanceLoc, i), schemaLoc+"/items")
+			}
+			ann.allItemsCovered = true
+		}
+	}
+
+	if sc.Contains != nil && sc.draft.Recognizes("contains") {
+		matches := 0
+		for i, item := range v {
+			sub := &Result{}
+			subCtx := ctx.branch(sub)
+			subCtx.validate(sc.Contains, item, ptrAppendIndex(instanceLoc, i), schemaLoc+"/contains")
+			if sub.Valid() {
+				matches++
+			}
+		}
+		min := uint64(1)
+		if sc.MinContains != nil && sc.draft.Recognizes("minContains") {
+			min = *sc.MinContains
+		}
+		if uint64(matches) < min {
+			ctx.result.addError(instanceLoc, schemaLoc+"/contains", "contains", "must contain at least %d matching item(s), found %d", min, matches)
+		}
+		if sc.MaxContains != nil && sc.draft.Recognizes("maxContains") && uint64(matches) > *sc.MaxContains {
+			ctx.result.addError(instanceLoc, schemaLoc+"/maxContains", "maxContains", "must contain at most %d matching item(s), found %d", *sc.MaxContains, matches)
+		}
+	}
+
+}
+
+// validateUnevaluatedItems applies sc.UnevaluatedItems to every item not already covered by
+// items/prefixItems/additionalItems or an in-place applicator (tracked in ann). It must
+// run after every other applicator has evaluated, so it's called from validate, not
+// validateArray.
+func (ctx *validationCtx) validateUnevaluatedItems(sc *schema, v []interface{}, instanceLoc, schemaLoc string, ann *annotations) {
+	if sc.UnevaluatedItems == nil || !sc.draft.Recognizes("unevaluatedItems") || ann.allItemsCovered {
+		return
+	}
+	for i := ann.evaluatedItems; i < len(v); i++ {
+		ctx.validate(sc.UnevaluatedItems, v[i], ptrAppendIndex(instanceLoc, i), schemaLoc+"/unevaluatedItems")
+	}
+	ann.allItemsCovered = true
+}
+
+func (ctx *validationCtx) validateObject(sc *schema, v map[string]interface{}, instanceLoc, schemaLoc string, ann *annotations) {
+	if sc.MaxProperties != nil && uint64(len(v)) > *sc.MaxProperties {
+		ctx.result.addError(instanceLoc, schemaLoc+"/maxProperties", "maxProperties", "must have no more than %d properties", *sc.MaxProperties)
+	}
+	if sc.MinProperties != nil && uint64(len(v)) < *sc.MinProperties {
+		ctx.result.addError(instanceLoc, schemaLoc+"/minProperties", "minProperties", "must have at least %d properties", *sc.MinProperties)
+	}
+	for _, req := range sc.Required {
+		if _, ok := v[req]; !ok {
+			ctx.result.addError(instanceLoc, schemaLoc+"/required", "required", "must have property %q", req)
+		}
+	}
+	if sc.draft.Recognizes("dependentRequired") {
+		for prop, deps := range sc.DependentRequired {
+			if _, ok := v[prop]; !ok {
+				continue
+			}
+			for _, dep := range deps {
+				if _, ok := v[dep]; !ok {
+					ctx.result.addError(instanceLoc, schemaLoc+"/dependentRequired", "dependentRequired", "property %q requires property %q", prop, dep)
+				}
+			}
+		}
+	}
+
+	matchedByPattern := map[string]bool{}
+	for name, val := range v {
+		if sub, ok := sc.Properties[name]; ok {
+			ctx.validate(sub, val, ptrAppendName(instanceLoc, name), schemaLoc+"/properties/"+jsonPointerEscape(name))
+			ann.evaluatedProps[name] = true
+		}
+		for pattern, sub := range sc.PatternProperties {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				continue
+			}
+			if re.MatchString(name) {
+				ctx.validate(sub, val, ptrAppendName(instanceLoc, name), schemaLoc+"/patternProperties/"+jsonPointerEscape(pattern))
+				ann.evaluatedProps[name] = true
+				matchedByPattern[name] = true
+			}
+		}
+	}
+
+	if sc.AdditionalProperties != nil {
+		for name, val := range v {
+			_, inProps := sc.Properties[name]
+			if inProps || matchedByPattern[name] {
+				continue
+			}
+			ctx.validate(sc.AdditionalProperties, val, ptrAppendName(instanceLoc, name), schemaLoc+"/additionalProperties")
+			ann.evaluatedProps[name] = true
+		}
+	}
+
+	if sc.PropertyNames != nil && sc.draft.Recognizes("propertyNames") {
+		for name := range v {
+			ctx.validate(sc.PropertyNames, name, ptrAppendName(instanceLoc, name), schemaLoc+"/propertyNames")
+		}
+	}
+
+}
+
+// validateUnevaluatedProperties applies sc.UnevaluatedProperties to every property not already
+// covered by properties/patternProperties/additionalProperties or an in-place applicator (tracked
+// in ann). It must run after every other applicator has evaluated, so it's called from validate,
+// not validateObject.
+func (ctx *validationCtx) validateUnevaluatedProperties(sc *schema, v map[string]interface{}, instanceLoc, schemaLoc string, ann *annotations) {
+	if sc.UnevaluatedProperties == nil || !sc.draft.Recognizes("unevaluatedProperties") {
+		return
+	}
+	for name, val := range v {
+		if ann.evaluatedProps[name] {
+			continue
+		}
+		ctx.validate(sc.UnevaluatedProperties, val, ptrAppendName(instanceLoc, name), schemaLoc+"/unevaluatedProperties")
+		ann.evaluatedProps[name] = true
+	}
+}
+
+func (ctx *validationCtx) validateApplicators(sc *schema, instance interface{}, instanceLoc, schemaLoc string, ann *annotations) {
+	for i, sub := range sc.AllOf {
+		childAnn := ctx.validate(sub, instance, instanceLoc, fmt.Sprintf("%s/allOf/%d", schemaLoc, i))
+		mergeAnnotations(ann, childAnn)
+	}
+
+	if len(sc.AnyOf) > 0 {
+		anyValid := false
+		for _, sub := range sc.AnyOf {
+			subRes := &Result{}
+			subCtx := ctx.branch(subRes)
+			childAnn := subCtx.validate(sub, instance, instanceLoc, schemaLoc+"/anyOf")
+			if subRes.Valid() {
+				anyValid = true
+				mergeAnnotations(ann, childAnn)
+			}
+		}
+		if !anyValid {
+			ctx.result.addError(instanceLoc, schemaLoc+"/anyOf", "anyOf", "must match at least one schema in anyOf")
+		}
+	}
+
+	if len(sc.OneOf) > 0 {
+		matches := 0
+		var matchedAnn *annotations
+		for _, sub := range sc.OneOf {
+			subRes := &Result{}
+			subCtx := ctx.branch(subRes)
+			childAnn := subCtx.validate(sub, instance, instanceLoc, schemaLoc+"/oneOf")
+			if subRes.Valid() {
+				matches++
+				matchedAnn = childAnn
+			}
+		}
+		if matches == 1 {
+			mergeAnnotations(ann, matchedAnn)
+		} else {
+			ctx.result.addError(instanceLoc, schemaLoc+"/oneOf", "oneOf", "must match exactly one schema in oneOf, matched %d", matches)
+		}
+	}
+
+	if sc.Not != nil {
+		subRes := &Result{}
+		subCtx := ctx.branch(subRes)
+		subCtx.validate(sc.Not, instance, instanceLoc, schemaLoc+"/not")
+		if subRes.Valid() {
+			ctx.result.addError(instanceLoc, schemaLoc+"/not", "not", "must not match the schema in not")
+		}
+	}
+
+	if sc.If != nil && sc.draft.Recognizes("if") {
+		ifRes := &Result{}
+		ifCtx := ctx.branch(ifRes)
+		ifAnn := ifCtx.validate(sc.If, instance, instanceLoc, schemaLoc+"/if")
+		if ifRes.Valid() {
+			mergeAnnotations(ann, ifAnn)
+			if sc.Then != nil {
+				childAnn := ctx.validate(sc.Then, instance, instanceLoc, schemaLoc+"/then")
+				mergeAnnotations(ann, childAnn)
+			}
+		} else if sc.Else != nil {
+			childAnn := ctx.validate(sc.Else, instance, instanceLoc, schemaLoc+"/else")
+			mergeAnnotations(ann, childAnn)
+		}
+	}
+
+	if obj, ok := instance.(map[string]interface{}); ok {
+		for prop, sub := range sc.DependentSchemas {
+			if _, present := obj[prop]; !present {
+				continue
+			}
+			childAnn := ctx.validate(sub, instance, instanceLoc, schemaLoc+"/dependentSchemas/"+jsonPointerEscape(prop))
+			mergeAnnotations(ann, childAnn)
+		}
+	}
+
+	if sc.Ref != nil {
+		if ctx.resolver == nil {
+			ctx.result.addError(instanceLoc, schemaLoc+"/$ref", "$ref", "cannot resolve $ref %q: no resolver configured", *sc.Ref)
+		} else if target, err := ctx.resolver.Resolve(*sc.Ref); err != nil {
+			ctx.result.addError(instanceLoc, schemaLoc+"/$ref", "$ref", "%s", err)
+		} else {
+			childAnn := ctx.validateScoped(target, instance, instanceLoc, schemaLoc+"/$ref")
+			mergeAnnotations(ann, childAnn)
+		}
+	}
+
+	if sc.RecursiveRef != nil && sc.draft.Recognizes("$recursiveRef") {
+		if *sc.RecursiveRef != "#" {
+			ctx.result.addError(instanceLoc, schemaLoc+"/$recursiveRef", "$recursiveRef", "unsupported $recursiveRef %q: only \"#\" is supported", *sc.RecursiveRef)
+		} else if ctx.resolver == nil {
+			ctx.result.addError(instanceLoc, schemaLoc+"/$recursiveRef", "$recursiveRef", "cannot resolve $recursiveRef: no resolver configured")
+		} else if target, err := ctx.recursiveRefTarget(); err != nil {
+			ctx.result.addError(instanceLoc, schemaLoc+"/$recursiveRef", "$recursiveRef", "%s", err)
+		} else {
+			childAnn := ctx.validateScoped(target, instance, instanceLoc, schemaLoc+"/$recursiveRef")
+			mergeAnnotations(ann, childAnn)
+		}
+	}
+}
+
+// jsonEqual reports whether two values decoded from JSON (via json.Unmarshal into
+// interface{}) are equal per the JSON Schema instance equality rules.
+func jsonEqual(a, b interface{}) bool {
+	switch av := a.(type) {
+	case float64:
+		bv, ok := b.(float64)
+		return ok && av == bv
+	case string:
+		bv, ok := b.(string)
+		return ok && av == bv
+	case bool:
+		bv, ok := b.(bool)
+		return ok && av == bv
+	case nil:
+		return b == nil
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			bvv, ok := bv[k]
+			if !ok || !jsonEqual(v, bvv) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// schemaFromRaw converts a value produced by decoding the "items" keyword into interface{}
+// (map[string]interface{} or bool, since the schema struct declares Items as interface{}
+// rather than *Schema) back into a *Schema by round-tripping it through JSON.
+func schemaFromRaw(raw interface{}) *Schema {
+	bs, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	s := &Schema{}
+	if err := json.Unmarshal(bs, s); err != nil {
+		return nil
+	}
+	return s
+}
+
+func ptrAppendName(base, name string) string {
+	return base + "/" + jsonPointerEscape(name)
+}
+
+func ptrAppendIndex(base string, i int) string {
+	return base + "/" + strconv.Itoa(i)
+}
+
+func jsonPointerEscape(s string) string {
+	s = strings.ReplaceAll(s, "~", "~0")
+	s = strings.ReplaceAll(s, "/", "~1")
+	return s
+}
+
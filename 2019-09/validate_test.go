@@ -0,0 +1,128 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func mustUnmarshalSchema(t *testing.T, src string) *Schema {
+	t.Helper()
+	var s Schema
+	if err := json.Unmarshal([]byte(src), &s); err != nil {
+		t.Fatalf("unmarshal %s: %v", src, err)
+	}
+	return &s
+}
+
+func mustUnmarshalInstance(t *testing.T, src string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(src), &v); err != nil {
+		t.Fatalf("unmarshal instance %s: %v", src, err)
+	}
+	return v
+}
+
+func TestValidateRef(t *testing.T) {
+	s := mustUnmarshalSchema(t, `{
+		"properties": {"age": {"$ref": "#/$defs/pos"}},
+		"$defs": {"pos": {"type": "integer", "minimum": 0}}
+	}`)
+
+	res, err := s.Validate(mustUnmarshalInstance(t, `{"age": -5}`))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if res.Valid() {
+		t.Fatalf("expected invalid, got valid for {\"age\": -5}")
+	}
+
+	res, err = s.Validate(mustUnmarshalInstance(t, `{"age": 5}`))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if !res.Valid() {
+		t.Fatalf("expected valid, got errors: %v", res.Errors)
+	}
+}
+
+func TestValidateEnumNonString(t *testing.T) {
+	s := mustUnmarshalSchema(t, `{"enum": [1, 2, 3]}`)
+
+	if res, _ := s.Validate(float64(2)); !res.Valid() {
+		t.Errorf("expected 2 to match enum, got errors: %v", res.Errors)
+	}
+	if res, _ := s.Validate(float64(5)); res.Valid() {
+		t.Errorf("expected 5 not to match enum")
+	}
+}
+
+func TestValidateOneOf(t *testing.T) {
+	s := mustUnmarshalSchema(t, `{
+		"oneOf": [
+			{"type": "string"},
+			{"type": "integer"}
+		]
+	}`)
+
+	if res, _ := s.Validate("hi"); !res.Valid() {
+		t.Errorf("expected string to match exactly one schema, got errors: %v", res.Errors)
+	}
+	if res, _ := s.Validate(true); res.Valid() {
+		t.Errorf("expected bool to match neither schema")
+	}
+}
+
+func TestValidateRefInsideAnyOf(t *testing.T) {
+	s := mustUnmarshalSchema(t, `{
+		"anyOf": [{"$ref": "#/$defs/str"}],
+		"$defs": {"str": {"type": "string"}}
+	}`)
+
+	res, err := s.Validate("hello")
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if !res.Valid() {
+		t.Errorf("expected a $ref inside anyOf to resolve against the same document, got errors: %v", res.Errors)
+	}
+}
+
+func TestValidateRecursiveRefWithoutAnchor(t *testing.T) {
+	s := mustUnmarshalSchema(t, `{
+		"type": "object",
+		"properties": {
+			"children": {"type": "array", "items": {"$recursiveRef": "#"}}
+		}
+	}`)
+
+	res, _ := s.Validate(mustUnmarshalInstance(t, `{"children": [{"children": []}]}`))
+	if !res.Valid() {
+		t.Errorf("expected a recursive tree to validate, got errors: %v", res.Errors)
+	}
+
+	res, _ = s.Validate(mustUnmarshalInstance(t, `{"children": [{"children": "not an array"}]}`))
+	if res.Valid() {
+		t.Errorf("expected the nested children to still be checked against the schema")
+	}
+}
+
+func TestValidateUnevaluatedPropertiesAcrossAllOf(t *testing.T) {
+	s := mustUnmarshalSchema(t, `{
+		"allOf": [
+			{"properties": {"name": {"type": "string"}}}
+		],
+		"properties": {"age": {"type": "integer"}},
+		"unevaluatedProperties": false
+	}`)
+
+	res, _ := s.Validate(mustUnmarshalInstance(t, `{"name": "a", "age": 1}`))
+	if !res.Valid() {
+		t.Errorf("expected properties evaluated via allOf to count, got errors: %v", res.Errors)
+	}
+
+	res, _ = s.Validate(mustUnmarshalInstance(t, `{"name": "a", "age": 1, "extra": true}`))
+	if res.Valid() {
+		t.Errorf("expected an uncovered property to be rejected by unevaluatedProperties")
+	}
+}
@@ -0,0 +1,54 @@
+// Command go-jsonschema-gen reads a JSON Schema document from a URI, resolving any $ref it
+// contains (including across documents), and writes the corresponding Go struct/enum/interface
+// definitions to stdout (or a file, via -o). Code is generated from the root document's own
+// subschemas; a property that holds only a $ref is typed interface{}.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/crunk1/go-jsonschema/2019-09"
+	"github.com/crunk1/go-jsonschema/codegen"
+)
+
+func main() {
+	var (
+		pkg      = flag.String("package", "generated", "package name for the generated file")
+		out      = flag.String("o", "", "output file (defaults to stdout)")
+		validate = flag.Bool("validation-tags", false, "emit go-playground/validator struct tags")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] <schema-uri>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cs, err := jsonschema.NewCompiler().Compile(flag.Arg(0))
+	if err != nil {
+		log.Fatalf("loading schema: %v", err)
+	}
+
+	src, err := codegen.Generate(cs.Root, codegen.Config{
+		PackageName:        *pkg,
+		EmitValidationTags: *validate,
+	})
+	if err != nil {
+		log.Fatalf("generating code: %v", err)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := ioutil.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatalf("writing %s: %v", *out, err)
+	}
+}
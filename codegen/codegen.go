@@ -0,0 +1,354 @@
+// Package codegen renders a compiled jsonschema.Schema into Go source: named structs for
+// objects, typed string constants for enums, sum-type interfaces for oneOf, and slices for
+// arrays. It is the schema-to-struct counterpart of packages like invopop/jsonschema, which
+// go the other direction.
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/crunk1/go-jsonschema/2019-09"
+)
+
+// Generate renders root as a Go source file in the given package, returning gofmt'd output.
+func Generate(root *jsonschema.Schema, cfg Config) ([]byte, error) {
+	g := &generator{
+		cfg:        cfg,
+		named:      map[string]string{},
+		byName:     map[string]bool{},
+		interfaces: map[string]bool{},
+	}
+	if _, err := g.emitNamed(root, "Root"); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, struct {
+		Package string
+		Decls   []string
+	}{cfg.PackageName, g.decls}); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(
+	`// Code generated by go-jsonschema-gen. DO NOT EDIT.
+
+package {{.Package}}
+{{range .Decls}}
+{{.}}
+{{end}}`))
+
+type generator struct {
+	cfg Config
+	// named maps a schema identity (its $id, else its $anchor) to the Go type name already
+	// generated for it, so a schema repeated under the same $id/$anchor gets one named type.
+	// Schemas with neither are not recorded. $ref is not followed: a schema that holds only a
+	// $ref renders as interface{}.
+	named map[string]string
+	// byName tracks which Go type names have been used, so derived names stay unique.
+	byName map[string]bool
+	// interfaces tracks which generated type names are oneOf marker interfaces, which are
+	// already nil-able and so should never be wrapped in an extra pointer.
+	interfaces map[string]bool
+	decls      []string
+}
+
+// emitType returns the Go type expression for s (e.g. "string", "[]int64", "*Address"),
+// generating and registering any named declarations it needs along the way.
+func (g *generator) emitType(s *jsonschema.Schema, nameHint string) (string, error) {
+	if s == nil {
+		return "interface{}", nil
+	}
+	if b, ok := s.AsBool(); ok {
+		if b {
+			return "interface{}", nil
+		}
+		return "struct{}", nil // the `false` schema: no value satisfies it
+	}
+
+	if key := refKey(s); key != "" {
+		if existing, ok := g.named[key]; ok {
+			if g.interfaces[existing] {
+				return existing, nil
+			}
+			return "*" + existing, nil
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		return g.emitNamed(s, nameHint)
+	}
+	if len(s.OneOf) > 0 {
+		return g.emitNamed(s, nameHint)
+	}
+
+	switch typeOf(s) {
+	case jsonschema.OBJECT:
+		return g.emitNamed(s, nameHint)
+	case jsonschema.ARRAY:
+		elemHint := strings.TrimSuffix(nameHint, "s")
+		elemSchema, err := schemaFromItems(s.Items)
+		if err != nil {
+			return "", err
+		}
+		elemType, err := g.emitType(elemSchema, elemHint)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elemType, nil
+	case jsonschema.STRING:
+		return "string", nil
+	case jsonschema.INTEGER:
+		return "int64", nil
+	case jsonschema.NUMBER:
+		return "float64", nil
+	case jsonschema.BOOLEAN:
+		return "bool", nil
+	case jsonschema.NULL:
+		return "interface{}", nil
+	default:
+		return "interface{}", nil
+	}
+}
+
+// emitNamed generates a top-level declaration for s (struct, enum, or oneOf interface) under
+// a unique Go type name derived from nameHint, returning that bare type name; renderStruct
+// puts it behind a pointer only for optional fields.
+func (g *generator) emitNamed(s *jsonschema.Schema, nameHint string) (string, error) {
+	name := g.typeNameFor(s, nameHint, refKey(s))
+
+	switch {
+	case len(s.Enum) > 0:
+		decl, err := g.renderEnum(s, name)
+		if err != nil {
+			return "", err
+		}
+		g.decls = append(g.decls, decl)
+		return name, nil
+	case len(s.OneOf) > 0:
+		decl, err := g.renderOneOf(s, name)
+		if err != nil {
+			return "", err
+		}
+		g.decls = append(g.decls, decl)
+		return name, nil
+	default:
+		decl, err := g.renderStruct(s, name)
+		if err != nil {
+			return "", err
+		}
+		g.decls = append(g.decls, decl)
+		return name, nil
+	}
+}
+
+func (g *generator) renderStruct(s *jsonschema.Schema, name string) (string, error) {
+	required := map[string]bool{}
+	for _, r := range s.Required {
+		required[r] = true
+	}
+
+	var props []string
+	for p := range s.Properties {
+		props = append(props, p)
+	}
+	sort.Strings(props)
+
+	var fields strings.Builder
+	for _, p := range props {
+		sub := s.Properties[p]
+		fieldType, err := g.emitType(sub, name+exportedName(p))
+		if err != nil {
+			return "", err
+		}
+		pointer := ""
+		if !required[p] {
+			pointer = "*"
+			if strings.HasPrefix(fieldType, "[]") || strings.HasPrefix(fieldType, "*") || fieldType == "interface{}" || g.interfaces[fieldType] {
+				pointer = "" // slices, pointers, and interfaces are already nil-able
+			}
+		}
+		tag := fmt.Sprintf("`json:\"%s", p)
+		if !required[p] {
+			tag += ",omitempty"
+		}
+		tag += "\""
+		if g.cfg.EmitValidationTags {
+			if vtag := validationTag(sub, required[p]); vtag != "" {
+				tag += fmt.Sprintf(` validate:"%s"`, vtag)
+			}
+		}
+		tag += "`"
+		fmt.Fprintf(&fields, "\t%s %s%s %s\n", exportedName(p), pointer, fieldType, tag)
+	}
+
+	return fmt.Sprintf("type %s struct {\n%s}\n", name, fields.String()), nil
+}
+
+// validationTag builds the github.com/go-playground/validator struct tag value for a property,
+// covering the subset of keywords Config.EmitValidationTags documents: required, min/max (string
+// length, numeric bounds, or array length, whichever the sub-schema constrains), and pattern.
+func validationTag(sub *jsonschema.Schema, required bool) string {
+	var parts []string
+	if required {
+		parts = append(parts, "required")
+	}
+	if _, ok := sub.AsBool(); ok {
+		return strings.Join(parts, ",")
+	}
+	if sub.MinLength != nil {
+		parts = append(parts, fmt.Sprintf("min=%d", *sub.MinLength))
+	}
+	if sub.MaxLength != nil {
+		parts = append(parts, fmt.Sprintf("max=%d", *sub.MaxLength))
+	}
+	if sub.Minimum != nil {
+		parts = append(parts, fmt.Sprintf("min=%v", *sub.Minimum))
+	}
+	if sub.Maximum != nil {
+		parts = append(parts, fmt.Sprintf("max=%v", *sub.Maximum))
+	}
+	if sub.MinItems != nil {
+		parts = append(parts, fmt.Sprintf("min=%d", *sub.MinItems))
+	}
+	if sub.MaxItems != nil {
+		parts = append(parts, fmt.Sprintf("max=%d", *sub.MaxItems))
+	}
+	if sub.Pattern != nil {
+		parts = append(parts, fmt.Sprintf("pattern=%s", *sub.Pattern))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (g *generator) renderEnum(s *jsonschema.Schema, name string) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "type %s string\n\nconst (\n", name)
+	for _, v := range s.Enum {
+		sv, ok := v.(string)
+		if !ok {
+			continue // a string-backed enum type can't represent a non-string member
+		}
+		fmt.Fprintf(&b, "\t%s%s %s = %q\n", name, exportedName(sv), name, sv)
+	}
+	b.WriteString(")\n")
+	return b.String(), nil
+}
+
+func (g *generator) renderOneOf(s *jsonschema.Schema, name string) (string, error) {
+	var b strings.Builder
+	marker := "is" + name
+	g.interfaces[name] = true
+	fmt.Fprintf(&b, "type %s interface {\n\t%s()\n}\n\n", name, marker)
+
+	for i, variant := range s.OneOf {
+		variantName, err := g.emitNamed(variant, fmt.Sprintf("%sVariant%d", name, i+1))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "func (*%s) %s() {}\n", variantName, marker)
+	}
+	return b.String(), nil
+}
+
+func typeOf(s *jsonschema.Schema) jsonschema.Type {
+	switch t := s.Type.(type) {
+	case string:
+		return jsonschema.Type(t)
+	case []interface{}:
+		if len(t) > 0 {
+			if str, ok := t[0].(string); ok {
+				return jsonschema.Type(str)
+			}
+		}
+	}
+	if len(s.Properties) > 0 {
+		return jsonschema.OBJECT
+	}
+	return ""
+}
+
+// schemaFromItems converts the raw value of an "items" keyword (decoded into interface{}
+// because jsonschema.Schema declares it untyped to also allow the tuple form) into a single
+// *jsonschema.Schema, by round-tripping it through JSON. Tuple-form "items" (one subschema
+// per index) is not yet supported by codegen and is treated as untyped.
+func schemaFromItems(raw interface{}) (*jsonschema.Schema, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	if _, isTuple := raw.([]interface{}); isTuple {
+		return nil, nil
+	}
+	bs, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	s := &jsonschema.Schema{}
+	if err := json.Unmarshal(bs, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// refKey returns the identity a $ref should be deduplicated by: the schema's $id if set,
+// else its $anchor, else "" (anonymous, not deduplicated).
+func refKey(s *jsonschema.Schema) string {
+	if s.ID != nil {
+		return *s.ID
+	}
+	if s.Anchor != nil {
+		return *s.Anchor
+	}
+	return ""
+}
+
+func (g *generator) typeNameFor(s *jsonschema.Schema, hint, key string) string {
+	if key != "" {
+		if override, ok := g.cfg.typeNameOverride(key); ok {
+			g.named[key] = override
+			g.byName[override] = true
+			return override
+		}
+	}
+	name := exportedName(hint)
+	if name == "" {
+		name = "Schema"
+	}
+	base := name
+	for i := 2; g.byName[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	g.byName[name] = true
+	if key != "" {
+		g.named[key] = name
+	}
+	return name
+}
+
+// exportedName converts a JSON property name, enum value, or schema identifier into an
+// exported Go identifier, e.g. "created-at" -> "CreatedAt".
+func exportedName(s string) string {
+	var b strings.Builder
+	upperNext := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			b.WriteRune(unicode.ToUpper(r))
+			upperNext = false
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,118 @@
+package codegen
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	jsonschema "github.com/crunk1/go-jsonschema/2019-09"
+)
+
+func mustSchema(t *testing.T, src string) *jsonschema.Schema {
+	t.Helper()
+	var s jsonschema.Schema
+	if err := json.Unmarshal([]byte(src), &s); err != nil {
+		t.Fatalf("unmarshal %s: %v", src, err)
+	}
+	return &s
+}
+
+func TestGenerateStruct(t *testing.T) {
+	root := mustSchema(t, `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"age": {"type": "integer"},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		},
+		"required": ["name"]
+	}`)
+
+	src, err := Generate(root, Config{PackageName: "generated"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	out := string(src)
+
+	for _, want := range []string{
+		"package generated",
+		"type Root struct {",
+		"Name string",
+		"*int64",
+		"[]string",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("generated source missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestGenerateEnum(t *testing.T) {
+	root := mustSchema(t, `{
+		"type": "object",
+		"properties": {
+			"status": {"enum": ["active", "inactive"]}
+		}
+	}`)
+
+	src, err := Generate(root, Config{PackageName: "generated"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	out := string(src)
+
+	outNorm := strings.Join(strings.Fields(out), " ")
+	for _, want := range []string{
+		"type RootStatus string",
+		`RootStatusActive RootStatus = "active"`,
+		`RootStatusInactive RootStatus = "inactive"`,
+	} {
+		if !strings.Contains(outNorm, want) {
+			t.Errorf("generated source missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestGenerateValidationTags(t *testing.T) {
+	root := mustSchema(t, `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 1, "maxLength": 50, "pattern": "^[a-z]+$"},
+			"age": {"type": "integer", "minimum": 0, "maximum": 150}
+		},
+		"required": ["name"]
+	}`)
+
+	src, err := Generate(root, Config{PackageName: "generated", EmitValidationTags: true})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	outNorm := strings.Join(strings.Fields(string(src)), " ")
+
+	for _, want := range []string{
+		`validate:"required,min=1,max=50,pattern=^[a-z]+$"`,
+		`validate:"min=0,max=150"`,
+	} {
+		if !strings.Contains(outNorm, want) {
+			t.Errorf("generated source missing %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestGenerateRefDeduplication(t *testing.T) {
+	root := mustSchema(t, `{
+		"type": "object",
+		"properties": {
+			"home": {"$id": "https://example.com/address", "type": "object", "properties": {"city": {"type": "string"}}},
+			"work": {"$id": "https://example.com/address", "type": "object", "properties": {"city": {"type": "string"}}}
+		}
+	}`)
+
+	src, err := Generate(root, Config{PackageName: "generated"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if n := strings.Count(string(src), "type RootHome struct") + strings.Count(string(src), "type RootWork struct"); n != 1 {
+		t.Errorf("expected exactly one named type for the shared $id, got %d in:\n%s", n, src)
+	}
+}
@@ -0,0 +1,22 @@
+package codegen
+
+// Config controls how Generate renders a schema into Go source.
+type Config struct {
+	// PackageName is the "package" clause of the generated file.
+	PackageName string
+	// TypeNames overrides the generated Go type name for a schema, keyed by its $id or
+	// $anchor. A schema with neither cannot be overridden. Types not named here get a name
+	// built from the enclosing type's name and the property name, e.g. "RootStatus".
+	TypeNames map[string]string
+	// EmitValidationTags adds `validate:"..."` struct tags (github.com/go-playground/validator
+	// syntax) alongside the `json:"..."` tag, covering required/min/max/pattern.
+	EmitValidationTags bool
+}
+
+func (c Config) typeNameOverride(key string) (string, bool) {
+	if c.TypeNames == nil {
+		return "", false
+	}
+	name, ok := c.TypeNames[key]
+	return name, ok
+}